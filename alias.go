@@ -0,0 +1,106 @@
+package sprockets
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// aliasTable holds the alias rules of a sprocket
+type aliasTable struct {
+	mutex sync.RWMutex
+	rules []*aliasRule
+}
+
+type aliasRule struct {
+	pattern string
+	target  string
+	re      *regexp.Regexp
+}
+
+func newAliasTable() *aliasTable {
+	return &aliasTable{}
+}
+
+// String returns the description of the rule ("pattern -> target"), empty for a nil rule
+func (ar *aliasRule) String() string {
+	if ar == nil {
+		return ""
+	}
+	return ar.pattern + " -> " + ar.target
+}
+
+// globRegexp turns a glob pattern into a regexp capturing its wildcards:
+// "*" matches anything but "/", "**" matches anything and "?" matches one character but "/"
+func globRegexp(pattern string) *regexp.Regexp {
+	expr := "^"
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr += "(.*)"
+			i++
+		case pattern[i] == '*':
+			expr += "([^/]*)"
+		case pattern[i] == '?':
+			expr += "[^/]"
+		default:
+			expr += regexp.QuoteMeta(pattern[i : i+1])
+		}
+	}
+	return regexp.MustCompile(expr + "$")
+}
+
+// AddAlias will add a rule replacing the asset paths matching pattern by target before they are searched
+// in the paths of their extension (ie: AddAlias("jquery", "vendor/jquery-3.3.1.min")).
+// The pattern is matched with or without the extension of the asset path, and can use the "*", "**" and "?"
+// wildcards, the text matched by each "*" or "**" replaces the corresponding one in target (ie: AddAlias("lib/*", "vendor/*/dist/*")).
+// The rules are tried in the order they were added, the first matching one is used.
+func (s *Sprocket) AddAlias(pattern, target string) {
+	s.aliases.mutex.Lock()
+	s.aliases.rules = append(s.aliases.rules, &aliasRule{pattern, target, globRegexp(pattern)})
+	s.aliases.mutex.Unlock()
+	s.processors.generation++
+}
+
+// apply returns assetPath replaced by the first matching rule, and that rule (nil if none matched)
+func (at *aliasTable) apply(assetPath string) (string, *aliasRule) {
+	at.mutex.RLock()
+	defer at.mutex.RUnlock()
+	ext := filepath.Ext(assetPath)
+	for _, rule := range at.rules {
+		if captures := rule.re.FindStringSubmatch(assetPath); captures != nil {
+			return rule.expand(captures[1:]), rule
+		}
+		if len(ext) == 0 {
+			continue
+		}
+		if captures := rule.re.FindStringSubmatch(strings.TrimSuffix(assetPath, ext)); captures != nil {
+			target := rule.expand(captures[1:])
+			if !strings.HasSuffix(target, ext) {
+				target += ext
+			}
+			return target, rule
+		}
+	}
+	return assetPath, nil
+}
+
+// expand returns the target of the rule with its wildcards replaced by captures
+func (ar *aliasRule) expand(captures []string) string {
+	ret := ""
+	for i := 0; i < len(ar.target); i++ {
+		if ar.target[i] != '*' {
+			ret += ar.target[i : i+1]
+			continue
+		}
+		if strings.HasPrefix(ar.target[i:], "**") {
+			i++
+		}
+		if len(captures) > 0 {
+			ret += captures[0]
+			captures = captures[1:]
+		}
+	}
+	return ret
+}
@@ -0,0 +1,101 @@
+package sprockets
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/znly/go-sprockets/types"
+)
+
+// Asset is a compiled asset with its metadata
+type Asset struct {
+	// LogicalPath is the path used to find the asset
+	LogicalPath string
+	// SourcePath is the resolved path of the asset source file (or of the public file if the source is missing)
+	SourcePath string
+	// ContentType is the content type base on the logical path extension (see GetContentType)
+	ContentType string
+	// MTime is the modification time of the asset source file
+	MTime time.Time
+	// Length is the length of the full content
+	Length int
+	// Integrity holds the Subresource Integrity digests of the full content
+	Integrity *types.Integrity
+
+	s           *Sprocket
+	fullContent []byte
+	body        []byte
+}
+
+// FindAsset will return the asset with its full content (with all its requirement) or an error if an error occured
+func (s *Sprocket) FindAsset(assetPath string) (*Asset, error) {
+	fullContent, integrity, err := s.getAsset(assetPath, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	sourcePath, _, err := s.resolvePath(assetPath, "", true)
+	if err != nil {
+		// Only the public file may be available
+		if sourcePath, _, err = s.resolvePath(assetPath, "", false); err != nil {
+			return nil, err
+		}
+	}
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Asset{
+		LogicalPath: filepath.ToSlash(strings.TrimPrefix(assetPath, "/")),
+		SourcePath:  sourcePath,
+		ContentType: s.GetContentType(assetPath),
+		MTime:       info.ModTime(),
+		Length:      len(fullContent),
+		Integrity:   integrity,
+		s:           s,
+		fullContent: fullContent,
+	}, nil
+}
+
+// Content returns the full content of the asset (with all its requirement)
+func (a *Asset) Content() []byte {
+	return a.fullContent
+}
+
+// Digest returns the hexadecimal SHA-256 of the full content, as used in the digest path
+func (a *Asset) Digest() string {
+	return hexDigest(a.fullContent)
+}
+
+// Body returns the content of the asset compiled without its requirements, it is read on first call
+func (a *Asset) Body() ([]byte, error) {
+	if a.body != nil {
+		return a.body, nil
+	}
+	body, err := a.s.getAssetBody(a.LogicalPath)
+	if err != nil {
+		return nil, err
+	}
+	a.body = body
+	return body, nil
+}
+
+// DataURL returns the full content as a base64 data url
+func (a *Asset) DataURL() string {
+	return "data:" + a.ContentType + ";base64," + base64.StdEncoding.EncodeToString(a.fullContent)
+}
+
+// Dependencies returns the ordered list of the resolved paths of the files bundled into the asset
+func (a *Asset) Dependencies() ([]string, error) {
+	debugAssets, err := a.s.GetDebugAssets(a.LogicalPath)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]string, len(debugAssets))
+	for i, debugAsset := range debugAssets {
+		ret[i] = debugAsset.Path
+	}
+	return ret, nil
+}
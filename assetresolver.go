@@ -0,0 +1,30 @@
+package sprockets
+
+import (
+	"strings"
+
+	"github.com/znly/go-sprockets/types"
+)
+
+// AssetResolver returns the urls of the sprocket assets to give to the compilers (ie: ScssSassCompiler.Assets)
+// urlPrefix is the url where the sprocket is served (ie: "/assets")
+func (s *Sprocket) AssetResolver(urlPrefix string) types.AssetResolver {
+	return &templateHelpers{s, strings.TrimSuffix(urlPrefix, "/"), false}
+}
+
+// AssetPath to implement AssetResolver
+func (th *templateHelpers) AssetPath(assetPath string) (string, error) {
+	if _, _, err := th.s.resolvePath(assetPath, "", false); err != nil {
+		return "", err
+	}
+	return th.assetPath(assetPath), nil
+}
+
+// AssetDataURL to implement AssetResolver
+func (th *templateHelpers) AssetDataURL(assetPath string) (string, error) {
+	asset, err := th.s.FindAsset(assetPath)
+	if err != nil {
+		return "", err
+	}
+	return asset.DataURL(), nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -25,6 +26,22 @@ type AssetsCache struct {
 type AssetCacheKey struct {
 	AssetPath string
 	Key       int64
+	// Variant distinguishes the full contents built from the same file with different options (ie: sass variables)
+	Variant string
+	// Generation distinguishes the contents built with different settings of the sprocket (processors, defines...)
+	// GetFullCache looks for the requirements of a full content in the same generation
+	Generation int
+}
+
+func (key *AssetCacheKey) cacheName() string {
+	name := key.AssetPath
+	if key.Generation != 0 {
+		name += "\x00" + strconv.Itoa(key.Generation)
+	}
+	if len(key.Variant) != 0 {
+		name += "\x00" + key.Variant
+	}
+	return name
 }
 
 type assetCache struct {
@@ -32,7 +49,10 @@ type assetCache struct {
 	FullContent []byte
 	Content     []byte
 	ExtInfo     *types.ExtensionInfo
+	Integrity   *types.Integrity
 	LastWrite   int64
+	// files which are not bundled but used to build FullContent (ie: sass imports)
+	Dependencies []string
 }
 
 // New return a new AssetCache structure
@@ -52,13 +72,13 @@ func (a *AssetsCache) GenerateCacheKey(assetPath string) (*AssetCacheKey, error)
 		return nil, err
 	}
 	key := info.ModTime().Unix()
-	return &AssetCacheKey{assetPath, key}, nil
+	return &AssetCacheKey{AssetPath: assetPath, Key: key}, nil
 }
 
 func (a *AssetsCache) readFromCache(key *AssetCacheKey) *assetCache {
 	a.mutex.RLock()
 	defer a.mutex.RUnlock()
-	if assetCaches, hit := a.cache[key.AssetPath]; hit {
+	if assetCaches, hit := a.cache[key.cacheName()]; hit {
 		if cache, hit := assetCaches.Get(key.Key); hit {
 			return cache
 		}
@@ -82,16 +102,29 @@ func (a *AssetsCache) ReadFromCache(key *AssetCacheKey) (content []byte, require
 }
 
 // WriteToCache will write the content of an asset into the cache
-func (a *AssetsCache) WriteToCache(key *AssetCacheKey, fullContent, content []byte, requires []types.RequireInterface, ExtInfo *types.ExtensionInfo) {
+// integrity is the Subresource Integrity of fullContent (nil if fullContent is nil)
+func (a *AssetsCache) WriteToCache(key *AssetCacheKey, fullContent, content []byte, requires []types.RequireInterface, ExtInfo *types.ExtensionInfo, integrity *types.Integrity) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 	var assetCaches *assetLru
 	var hit bool
-	if assetCaches, hit = a.cache[key.AssetPath]; !hit {
+	if assetCaches, hit = a.cache[key.cacheName()]; !hit {
 		assetCaches = newAssetLru(5)
-		a.cache[key.AssetPath] = assetCaches
+		a.cache[key.cacheName()] = assetCaches
 	}
-	assetCaches.Add(key.Key, &assetCache{requires, fullContent, content, ExtInfo, time.Now().Unix()})
+	assetCaches.Add(key.Key, &assetCache{requires, fullContent, content, ExtInfo, integrity, time.Now().Unix(), nil})
+}
+
+// SetDependencies will record the files used to build the full content of an asset which are not bundled into it
+// (ie: sass imports), the full content is outdated when one of them is modified
+func (a *AssetsCache) SetDependencies(key *AssetCacheKey, dependencies []string) {
+	cache := a.readFromCache(key)
+	if cache == nil {
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	cache.Dependencies = dependencies
 }
 
 // GetFullCache will return the full content of a Cache if it s available and not outdated
@@ -100,6 +133,15 @@ func (a *AssetsCache) GetFullCache(key *AssetCacheKey) ([]byte, error) {
 	if cache == nil || cache.FullContent == nil {
 		return nil, nil
 	}
+	a.mutex.RLock()
+	dependencies := cache.Dependencies
+	a.mutex.RUnlock()
+	for _, dependency := range dependencies {
+		info, err := os.Stat(dependency)
+		if err != nil || info.ModTime().Unix() > cache.LastWrite {
+			return nil, nil
+		}
+	}
 	graph := dependencygraph.Graph{}
 	_, err := graph.Walk(key.AssetPath, func(curPath, parentPath string, g *dependencygraph.Graph) error {
 		curKey, err := a.GenerateCacheKey(curPath)
@@ -109,6 +151,10 @@ func (a *AssetsCache) GetFullCache(key *AssetCacheKey) ([]byte, error) {
 		if curKey.Key > cache.LastWrite {
 			return errMustRebuildCache
 		}
+		curKey.Generation = key.Generation
+		if curPath == key.AssetPath {
+			curKey.Variant = key.Variant
+		}
 		curCache := a.readFromCache(curKey)
 		if curCache == nil {
 			return errMustRebuildCache
@@ -137,3 +183,13 @@ func (a *AssetsCache) GetFullCache(key *AssetCacheKey) ([]byte, error) {
 	}
 	return cache.FullContent, nil
 }
+
+// GetIntegrity will return the Subresource Integrity of the full content of a Cache, nil if it is not available
+// It does not check if the cache is outdated, use GetFullCache first
+func (a *AssetsCache) GetIntegrity(key *AssetCacheKey) *types.Integrity {
+	cache := a.readFromCache(key)
+	if cache == nil {
+		return nil
+	}
+	return cache.Integrity
+}
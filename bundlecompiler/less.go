@@ -0,0 +1,132 @@
+package bundlecompiler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/znly/go-sprockets/jsvm"
+	"github.com/znly/go-sprockets/types"
+	duktape "gopkg.in/olebedev/go-duktape.v3"
+)
+
+// LessCompiler is here to compile less bundled files with less.js running in a pool of duktape contexts
+// NewDefaultLessCompiler runs the embedded less.js, NewLessCompiler the one given
+type LessCompiler struct {
+	pool *jsvm.Pool
+}
+
+// the browser objects less.js needs to load
+const lessEnvironment = `var window = this;
+var document = {currentScript: null, getElementsByTagName: function() { return []; }, location: {href: "file:///", protocol: "file:", hostname: "", port: ""}};
+var location = document.location;
+window.less = {env: "production", logLevel: 0};`
+
+// the file manager resolving the @import with the lessImport go function
+const lessCompile = `function SprocketsFileManager() {}
+SprocketsFileManager.prototype = new less.AbstractFileManager();
+SprocketsFileManager.prototype.supports = function() { return true; };
+SprocketsFileManager.prototype.supportsSync = function() { return true; };
+SprocketsFileManager.prototype.loadFileSync = function(filename, currentDirectory) {
+	var result = JSON.parse(lessImport(filename, currentDirectory));
+	if (result.error) {
+		return {error: {type: "File", message: result.error}};
+	}
+	return {contents: result.contents, filename: result.filename};
+};
+SprocketsFileManager.prototype.loadFile = function(filename, currentDirectory, options, environment, callback) {
+	var result = this.loadFileSync(filename, currentDirectory);
+	if (result.error) {
+		callback(result.error);
+	} else {
+		callback(null, result);
+	}
+};
+var sprocketsPlugin = {install: function(less, pluginManager) { pluginManager.addFileManager(new SprocketsFileManager()); }};
+function lessCompile(content, path) {
+	var ret = {error: "less did not compile " + path + " synchronously"};
+	try {
+		less.render(content, {filename: path, syncImport: true, plugins: [sprocketsPlugin]}, function(e, output) {
+			if (!e) {
+				ret = {code: output.css};
+			} else if (e.line) {
+				ret = {error: (e.filename || path) + ":" + e.line + ":" + (e.column + 1) + ": " + e.message};
+			} else {
+				ret = {error: (e.filename || path) + ": " + e.message};
+			}
+		});
+	} catch(e) {
+		ret = {error: path + ": " + e.toString()};
+	}
+	return JSON.stringify(ret);
+}`
+
+// NewLessCompiler returns a new LessCompiler running the less.js source in at most poolSize contexts
+func NewLessCompiler(lessJS []byte, poolSize int) (*LessCompiler, error) {
+	pool, err := jsvm.NewPool(poolSize, lessEnvironment, string(lessJS), lessCompile)
+	if err != nil {
+		return nil, errors.New("could not load less: " + err.Error())
+	}
+	return &LessCompiler{pool}, nil
+}
+
+// NewDefaultLessCompiler returns a new LessCompiler running the embedded less.js in at most poolSize contexts
+func NewDefaultLessCompiler(poolSize int) (*LessCompiler, error) {
+	lessJS, err := jsvm.Gunzip(libless)
+	if err != nil {
+		return nil, err
+	}
+	return NewLessCompiler([]byte(lessJS), poolSize)
+}
+
+// Process to implement ContentTreatmentInterface
+func (lc *LessCompiler) Process(content []byte, path string) ([]byte, error) {
+	return lc.ProcessWithImports(content, path, nil)
+}
+
+// ProcessWithImports to implement ImporterInterface
+// the @import are resolved with resolve, or relatively to the importing file if resolve is nil
+func (lc *LessCompiler) ProcessWithImports(content []byte, path string, resolve types.ImportResolver) ([]byte, error) {
+	lessImport := func(ctx *duktape.Context) int {
+		ctx.PushString(loadLessImport(resolve, ctx.RequireString(0), ctx.RequireString(1), path))
+		return 1
+	}
+	code, err := lc.pool.Call("lessCompile", map[string]func(*duktape.Context) int{"lessImport": lessImport}, string(content), path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(code), nil
+}
+
+// loadLessImport returns the JSON of the file imported from currentDirectory (with its filename and contents, or an error)
+func loadLessImport(resolve types.ImportResolver, importPath, currentDirectory, rootPath string) string {
+	// only the directory of the parent path is used to resolve the import
+	parentPath := rootPath
+	if len(currentDirectory) > 0 {
+		parentPath = filepath.Join(currentDirectory, filepath.Base(rootPath))
+	}
+	var ret struct {
+		Filename string `json:"filename,omitempty"`
+		Contents string `json:"contents"`
+		Error    string `json:"error,omitempty"`
+	}
+	var importedPath string
+	var err error
+	if resolve != nil {
+		importedPath, err = resolve(importPath, parentPath)
+	} else if importedPath = filepath.Join(filepath.Dir(parentPath), importPath); filepath.Ext(importedPath) == "" {
+		importedPath += ".less"
+	}
+	var content []byte
+	if err == nil {
+		content, err = ioutil.ReadFile(importedPath)
+	}
+	if err != nil {
+		ret.Error = importPath + ": " + err.Error()
+	} else {
+		ret.Filename, ret.Contents = importedPath, string(content)
+	}
+	out, _ := json.Marshal(ret)
+	return string(out)
+}
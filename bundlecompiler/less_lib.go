@@ -0,0 +1,5 @@
+package bundlecompiler
+
+// libless is the gzipped dist/less.js of less, written by go generate in the jsvm package
+// (it is not in the repository, NewDefaultLessCompiler returns jsvm.ErrNotEmbedded until then)
+var libless []byte
@@ -2,8 +2,11 @@ package bundlecompiler
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 
 	libsass "github.com/wellington/go-libsass"
+	"github.com/znly/go-sprockets/types"
 )
 
 // ScssSassCompiler is here to compile scss bundled file (sass must be transformed into scss first)
@@ -11,16 +14,61 @@ import (
 type ScssSassCompiler struct {
 	LineNumbers bool
 	DebugInfo   bool
+	OutputStyle string // nested (default), expanded, compact or compressed
+	Precision   int    // precision of the decimal numbers, libsass default if 0
+	// Assets enables the asset-path, asset-url, image-url, font-url and asset-data-url functions
+	// (see Sprocket.AssetResolver)
+	Assets types.AssetResolver
 }
 
 // Process to implement ContentTreatmentInterface
 func (ssc *ScssSassCompiler) Process(content []byte, path string) ([]byte, error) {
+	return ssc.ProcessWithImports(content, path, nil)
+}
+
+// ProcessWithImports to implement ImporterInterface
+// the @import are resolved with resolve, libsass resolves them itself if resolve is nil or can't find them
+func (ssc *ScssSassCompiler) ProcessWithImports(content []byte, path string, resolve types.ImportResolver) ([]byte, error) {
+	outputStyle := ssc.OutputStyle
+	if outputStyle == "" {
+		outputStyle = "nested"
+	}
+	style, ok := libsass.Style[outputStyle]
+	if !ok {
+		return nil, fmt.Errorf("unknown sass output style %q", ssc.OutputStyle)
+	}
 	in := bytes.NewBuffer(content)
 	out := &bytes.Buffer{}
-	comp, err := libsass.New(out, in, libsass.OutputStyle(libsass.Style["nested"]), libsass.Comments(ssc.LineNumbers || ssc.DebugInfo))
+	comp, err := libsass.New(out, in, libsass.OutputStyle(style), libsass.Comments(ssc.LineNumbers || ssc.DebugInfo))
 	if err != nil {
 		return nil, err
 	}
+	if ssc.Precision > 0 {
+		if err := comp.Option(libsass.Precision(ssc.Precision)); err != nil {
+			return nil, err
+		}
+	}
+	if ssc.Assets != nil {
+		if err := comp.Option(libsass.Payload(context.WithValue(context.Background(), assetsKey{}, ssc.Assets))); err != nil {
+			return nil, err
+		}
+	}
+	if resolve != nil {
+		imports := libsass.NewImportsWithResolver(func(url, prev string) (string, string, bool) {
+			if prev == "stdin" || prev == "" {
+				prev = path
+			}
+			importedPath, err := resolve(url, prev)
+			if err != nil {
+				return "", "", false
+			}
+			// an empty body makes libsass read the file
+			return importedPath, "", true
+		})
+		if err := comp.Option(libsass.ImportsOption(imports)); err != nil {
+			return nil, err
+		}
+	}
 	if err := comp.Run(); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,68 @@
+package bundlecompiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	libsass "github.com/wellington/go-libsass"
+	"github.com/znly/go-sprockets/types"
+)
+
+type assetsKey struct{}
+
+var errNoAssetResolver = errors.New("no asset resolver given to the sass compiler")
+
+// cssStringReplacer escapes a string to quote it in css (%q would use the go escapes)
+var cssStringReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", `\D `, "\n", `\A `)
+
+// the asset helpers are registered once for all the compilers
+// they find the AssetResolver of the running compiler in its payload
+func init() {
+	libsass.RegisterSassFunc("asset-path($name)", sassAssetFunc(false, func(assets types.AssetResolver, name string) (string, error) {
+		return assets.AssetPath(name)
+	}))
+	for _, sign := range []string{"asset-url($name)", "image-url($name)", "font-url($name)"} {
+		libsass.RegisterSassFunc(sign, sassAssetFunc(true, func(assets types.AssetResolver, name string) (string, error) {
+			return assets.AssetPath(name)
+		}))
+	}
+	libsass.RegisterSassFunc("asset-data-url($name)", sassAssetFunc(true, func(assets types.AssetResolver, name string) (string, error) {
+		return assets.AssetDataURL(name)
+	}))
+}
+
+// sassAssetFunc turns f into a sass function taking the asset name, its result is wrapped into url() if asURL is true
+func sassAssetFunc(asURL bool, f func(assets types.AssetResolver, name string) (string, error)) libsass.SassFunc {
+	return func(ctx context.Context, in libsass.SassValue) (*libsass.SassValue, error) {
+		comp, err := libsass.CompFromCtx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		payload := comp.Payload()
+		if payload == nil {
+			return nil, errNoAssetResolver
+		}
+		assets, ok := payload.Value(assetsKey{}).(types.AssetResolver)
+		if !ok {
+			return nil, errNoAssetResolver
+		}
+		var name string
+		if err := libsass.Unmarshal(in, &name); err != nil {
+			return nil, err
+		}
+		ret, err := f(assets, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		if asURL {
+			ret = `url("` + cssStringReplacer.Replace(ret) + `")`
+		}
+		out, err := libsass.Marshal(ret)
+		if err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+}
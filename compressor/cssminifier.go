@@ -0,0 +1,134 @@
+package compressor
+
+import (
+	"bytes"
+	"strings"
+)
+
+// CSSMinifier is here to minify css bundles which are not compiled by libsass
+// it removes whitespaces, comments (except /*! */ ones, kept for licenses) and useless semicolons.
+// Register it as a compressor to use it in production mode only:
+//
+//	s.RegisterCompressor("text/css", &compressor.CSSMinifier{})
+type CSSMinifier struct{}
+
+// punctuators around which whitespaces can always be removed
+const cssStrippedPunctuators = "{};,!"
+
+// combinators around which whitespaces can be removed outside of parenthesis (ie: not in calc())
+const cssStrippedCombinators = ">~+"
+
+func isCSSWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isCSSNamePart(c byte) bool {
+	return isJSIdentPart(c) || c == '-'
+}
+
+// Process to implement ContentTreatmentInterface
+func (cm *CSSMinifier) Process(content []byte, path string) ([]byte, error) {
+	var buf bytes.Buffer
+	space := false     // whitespaces are pending
+	stripAfter := true // the last written token doesn't need a whitespace after it
+	parenthesis := 0
+	write := func(token string, stripBefore, strip bool) {
+		if space && !stripAfter && !stripBefore {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(token)
+		space, stripAfter = false, strip
+	}
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case isCSSWhitespace(c):
+			space = true
+			i++
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := bytes.Index(content[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, errUnterminatedComment
+			}
+			end += i + 4
+			if content[i+2] == '!' {
+				write(string(content[i:end]), false, true)
+			} else {
+				space = true
+			}
+			i = end
+		case c == '"' || c == '\'':
+			end, err := scanJSString(content, i)
+			if err != nil {
+				return nil, err
+			}
+			write(string(content[i:end]), false, false)
+			i = end
+		case c == '\\' && i+1 < len(content):
+			write(string(content[i:i+2]), false, false)
+			i += 2
+		case (c == 'u' || c == 'U') && (i == 0 || !isCSSNamePart(content[i-1])) && i+4 <= len(content) && strings.EqualFold(string(content[i:i+4]), "url("):
+			end, ok := scanCSSUnquotedURL(content, i+4)
+			if !ok {
+				write(string(content[i:i+4]), false, true)
+				parenthesis++
+				i += 4
+				continue
+			}
+			write("url("+strings.TrimSpace(string(content[i+4:end]))+")", false, false)
+			i = end + 1
+		case strings.IndexByte(cssStrippedPunctuators, c) >= 0 || parenthesis == 0 && strings.IndexByte(cssStrippedCombinators, c) >= 0:
+			if c == ';' || c == '}' {
+				if b := buf.Bytes(); len(b) > 0 && b[len(b)-1] == ';' {
+					buf.Truncate(len(b) - 1)
+				}
+			}
+			if c == ';' && buf.Len() > 0 && buf.Bytes()[buf.Len()-1] == '{' {
+				space = false
+			} else {
+				write(string(c), true, true)
+			}
+			i++
+		case c == '(' || c == ':':
+			if c == '(' {
+				parenthesis++
+			}
+			write(string(c), false, true)
+			i++
+		case c == ')':
+			if parenthesis > 0 {
+				parenthesis--
+			}
+			write(string(c), true, false)
+			i++
+		case c == '0' && i+2 < len(content) && content[i+1] == '.' && isDigit(content[i+2]) && (i == 0 || !isCSSNamePart(content[i-1]) && content[i-1] != '.'):
+			// 0.5 is written .5
+			write(".", false, false)
+			i += 2
+		default:
+			write(string(c), false, false)
+			i++
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// scanCSSUnquotedURL returns the index of the ")" ending the unquoted url starting at src[i],
+// false if the url is quoted
+func scanCSSUnquotedURL(src []byte, i int) (int, bool) {
+	for i < len(src) && isCSSWhitespace(src[i]) {
+		i++
+	}
+	if i < len(src) && (src[i] == '"' || src[i] == '\'') {
+		return 0, false
+	}
+	for ; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case ')':
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,35 @@
+package compressor
+
+import "testing"
+
+func TestCSSMinifier(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"whitespaces", "a  {  color: red ;  }\n\nb { c: d }", "a{color:red}b{c:d}"},
+		{"strings", "a { content: \"  a  ;  b \"; font-family: 'x  y' }", "a{content:\"  a  ;  b \";font-family:'x  y'}"},
+		{"escaped quotes", `a { content: "a \"  b" }`, `a{content:"a \"  b"}`},
+		{"calc whitespaces", "a { width: calc( 100% - 10px ); height: calc(1px + 2px) }", "a{width:calc(100% - 10px);height:calc(1px + 2px)}"},
+		{"important", "a { color: red !important ; }", "a{color:red!important}"},
+		{"important with a space", "a { color: red ! important; }", "a{color:red!important}"},
+		{"comments", "/* drop */ a { b: c /* drop */ }", "a{b:c}"},
+		{"preserved comment", "/*! license */\na { b: c }", "/*! license */a{b:c}"},
+		{"combinators", "a > b + c ~ d { e: f }", "a>b+c~d{e:f}"},
+		{"descendant pseudo class", "a :hover { b: c }", "a :hover{b:c}"},
+		{"selector list", "a:hover , b::after { c: d }", "a:hover,b::after{c:d}"},
+		{"media query", "@media screen and (max-width: 100px) { a { b: c; } }", "@media screen and (max-width:100px){a{b:c}}"},
+		{"useless semicolons", "a { b: c;; }", "a{b:c}"},
+	}
+	for _, test := range tests {
+		out, err := (&CSSMinifier{}).Process([]byte(test.src), "test.css")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if string(out) != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, out, test.want)
+		}
+	}
+}
@@ -0,0 +1,727 @@
+package compressor
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSDefiner is here to replace compile-time constants in javascript files, as the webpack DefinePlugin does
+// The identifiers or member expressions of Defines (ie: "__DEV__", "process.env.NODE_ENV") are replaced by
+// their value as a javascript literal, unless a local binding shadows them, then the if statements with
+// a constant condition lose their dead branch.
+// If DropConsole is true, the console.* calls and debugger statements are removed too.
+// Register it on each javascript file (see Sprocket.SetJSDefines):
+//
+//	s.RegisterPostprocessor("application/javascript", &compressor.JSDefiner{Defines: map[string]interface{}{"__DEV__": false}})
+type JSDefiner struct {
+	Defines     map[string]interface{}
+	DropConsole bool
+}
+
+// jsEdit replaces the bytes from start to end of a source
+type jsEdit struct {
+	start, end int
+	text       string
+}
+
+// jsUndefined is the javascript undefined value when evaluating a condition
+type jsUndefined struct{}
+
+// Process to implement ContentTreatmentInterface
+func (jd *JSDefiner) Process(content []byte, path string) ([]byte, error) {
+	var err error
+	if len(jd.Defines) > 0 {
+		if content, err = jd.replaceDefines(content); err != nil {
+			return nil, err
+		}
+	}
+	if content, err = rewriteJS(content, stripDeadBranches); err != nil {
+		return nil, err
+	}
+	if jd.DropConsole {
+		if content, err = rewriteJS(content, dropConsole); err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+// rewriteJS applies to src the edits found by find in its tokens
+func rewriteJS(src []byte, find func(tokens []*jsToken, closeOf []int) []jsEdit) ([]byte, error) {
+	tokens, err := tokenizeJS(src)
+	if err != nil {
+		return nil, err
+	}
+	closeOf, ok := matchJSBrackets(tokens)
+	if !ok {
+		return src, nil
+	}
+	return applyJSEdits(src, find(tokens, closeOf)), nil
+}
+
+func (jd *JSDefiner) replaceDefines(src []byte) ([]byte, error) {
+	tokens, err := tokenizeJS(src)
+	if err != nil {
+		return nil, err
+	}
+	// the longest names first, so "process.env.NODE_ENV" is replaced before "process.env"
+	names := make([]string, 0, len(jd.Defines))
+	for name := range jd.Defines {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	literals := make(map[string]string, len(names))
+	for _, name := range names {
+		literal, err := json.Marshal(jd.Defines[name])
+		if err != nil {
+			return nil, err
+		}
+		literals[name] = string(literal)
+	}
+	closeOf, ok := matchJSBrackets(tokens)
+	if !ok {
+		return src, nil
+	}
+	enclosing := jsEnclosing(tokens, closeOf)
+	roots := make(map[string]bool, len(names))
+	for _, name := range names {
+		roots[strings.SplitN(name, ".", 2)[0]] = true
+	}
+	local := (&jsScopes{tokens, closeOf, enclosing}).localBindings(roots)
+	var edits []jsEdit
+	for k := 0; k < len(tokens); k++ {
+		if tokens[k].kind != jsIdent || isMemberName(tokens, k) || k > 0 && isDeclarationKeyword(tokens[k-1]) ||
+			isJSBinding(tokens, closeOf, enclosing, k) || local.shadows(tokens[k].text, k) {
+			continue
+		}
+		for _, name := range names {
+			last, ok := matchMemberExpression(tokens, k, strings.Split(name, "."))
+			if !ok || last+1 < len(tokens) && (tokens[last+1].text == "=" || tokens[last+1].text == "++" || tokens[last+1].text == "--") {
+				continue
+			}
+			edits = append(edits, jsEdit{tokens[k].start, tokenEnd(tokens[last]), literals[name]})
+			k = last
+			break
+		}
+	}
+	return applyJSEdits(src, edits), nil
+}
+
+func tokenEnd(t *jsToken) int {
+	return t.start + len(t.text)
+}
+
+// isMemberName tells if the identifier at index k is a property name after a "." or a "?."
+func isMemberName(tokens []*jsToken, k int) bool {
+	return k > 0 && tokens[k-1].kind == jsPunct && (tokens[k-1].text == "." || tokens[k-1].text == "?.")
+}
+
+func isDeclarationKeyword(t *jsToken) bool {
+	return t.kind == jsIdent && (t.text == "var" || t.text == "let" || t.text == "const" || t.text == "function" || t.text == "class")
+}
+
+// jsEnclosing returns for each token the index of the innermost bracket opened around it, -1 if none
+func jsEnclosing(tokens []*jsToken, closeOf []int) []int {
+	enclosing := make([]int, len(tokens))
+	var stack []int
+	for k, t := range tokens {
+		if t.kind == jsPunct && (t.text == ")" || t.text == "]" || t.text == "}") {
+			stack = stack[:len(stack)-1]
+		}
+		enclosing[k] = -1
+		if len(stack) > 0 {
+			enclosing[k] = stack[len(stack)-1]
+		}
+		if t.kind == jsPunct && (t.text == "(" || t.text == "[" || t.text == "{") {
+			stack = append(stack, k)
+		}
+	}
+	return enclosing
+}
+
+// isJSBinding tells if the identifier at index k is not a reference to a variable but a parameter, a destructuring target,
+// a shorthand property or a property key, which must not be replaced by a value
+func isJSBinding(tokens []*jsToken, closeOf, enclosing []int, k int) bool {
+	var prev, next string
+	if k > 0 {
+		prev = tokens[k-1].text
+	}
+	if k+1 < len(tokens) {
+		next = tokens[k+1].text
+	}
+	if next == "=>" {
+		return true
+	}
+	o := enclosing[k]
+	if o < 0 {
+		return false
+	}
+	switch tokens[o].text {
+	case "(":
+		return isJSParams(tokens, closeOf, o) && (prev == "(" || prev == "," || prev == "...") &&
+			(next == "," || next == ")" || next == "=")
+	case "{":
+		if (prev == "{" || prev == ",") && (next == ":" || next == "," || next == "}" || next == "=") {
+			return true
+		}
+		return prev == ":" && isJSPattern(tokens, closeOf, enclosing, o) && (next == "," || next == "}" || next == "=")
+	case "[":
+		return isJSPattern(tokens, closeOf, enclosing, o) && (prev == "[" || prev == "," || prev == "...") &&
+			(next == "," || next == "]" || next == "=")
+	}
+	return false
+}
+
+// isJSParams tells if the "(" at index o opens the parameters of a function, an arrow function, a method or a catch clause
+func isJSParams(tokens []*jsToken, closeOf []int, o int) bool {
+	c := closeOf[o]
+	if c+1 < len(tokens) && tokens[c+1].text == "=>" {
+		return true
+	}
+	if o == 0 || tokens[o-1].kind != jsIdent {
+		return false
+	}
+	switch tokens[o-1].text {
+	case "function":
+		return true
+	case "if", "while", "for", "switch", "with":
+		return false
+	}
+	return o > 1 && tokens[o-2].kind == jsIdent && tokens[o-2].text == "function" ||
+		c+1 < len(tokens) && tokens[c+1].text == "{"
+}
+
+// isJSPattern tells if the "{" or "[" at index o opens a destructuring pattern
+func isJSPattern(tokens []*jsToken, closeOf, enclosing []int, o int) bool {
+	if o > 0 && isDeclarationKeyword(tokens[o-1]) {
+		return true
+	}
+	if c := closeOf[o]; c+1 < len(tokens) && tokens[c+1].kind == jsPunct && tokens[c+1].text == "=" {
+		return true
+	}
+	parent := enclosing[o]
+	if o == 0 || parent < 0 {
+		return false
+	}
+	prev := tokens[o-1].text
+	switch tokens[parent].text {
+	case "(":
+		return (prev == "(" || prev == ",") && isJSParams(tokens, closeOf, parent)
+	case "{", "[":
+		return (prev == "{" || prev == "[" || prev == "," || prev == ":" || prev == "...") &&
+			isJSPattern(tokens, closeOf, enclosing, parent)
+	}
+	return false
+}
+
+// jsScopes finds the scopes of the bindings of a tokenized source
+type jsScopes struct {
+	tokens    []*jsToken
+	closeOf   []int
+	enclosing []int
+}
+
+// jsBindings holds for some names the ranges of tokens (first and last index) where a local binding shadows them
+type jsBindings map[string][][2]int
+
+// shadows tells if the identifier name at index k is a reference to a local binding
+func (b jsBindings) shadows(name string, k int) bool {
+	for _, scope := range b[name] {
+		if k >= scope[0] && k <= scope[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// localBindings returns the scopes of the variables, functions, classes and parameters declared with one of names
+// The var declarations are scoped to their function, the other ones to their block.
+func (js *jsScopes) localBindings(names map[string]bool) jsBindings {
+	bindings := jsBindings{}
+	for k, t := range js.tokens {
+		if t.kind != jsIdent || !names[t.text] || isMemberName(js.tokens, k) {
+			continue
+		}
+		if scope, ok := js.bindingScope(k); ok {
+			bindings[t.text] = append(bindings[t.text], scope)
+		}
+	}
+	return bindings
+}
+
+// bindingScope returns the scope of the identifier at index k if it is declared there
+func (js *jsScopes) bindingScope(k int) ([2]int, bool) {
+	tokens := js.tokens
+	if k > 0 && isDeclarationKeyword(tokens[k-1]) {
+		return js.declarationScope(k - 1), true
+	}
+	if k+1 < len(tokens) && tokens[k+1].text == "=>" {
+		return [2]int{k, js.arrowEnd(k + 1)}, true
+	}
+	if !isJSBinding(tokens, js.closeOf, js.enclosing, k) {
+		return [2]int{}, false
+	}
+	o := js.enclosing[k]
+	if tokens[o].text == "(" {
+		return js.paramsScope(o), true
+	}
+	// a property key or a shorthand property of an object literal
+	if tokens[o].text == "{" && (k+1 < len(tokens) && tokens[k+1].text == ":" || !isJSPattern(tokens, js.closeOf, js.enclosing, o)) {
+		return [2]int{}, false
+	}
+	for p := js.enclosing[o]; p >= 0 && (tokens[p].text == "{" || tokens[p].text == "[") && isJSPattern(tokens, js.closeOf, js.enclosing, p); p = js.enclosing[p] {
+		o = p
+	}
+	switch p := js.enclosing[o]; {
+	case o > 0 && isDeclarationKeyword(tokens[o-1]):
+		return js.declarationScope(o - 1), true
+	case p >= 0 && tokens[p].text == "(" && isJSParams(tokens, js.closeOf, p):
+		return js.paramsScope(p), true
+	}
+	return [2]int{}, false // the target of a destructuring assignment
+}
+
+// declarationScope returns the scope of the names declared by the keyword at index d
+func (js *jsScopes) declarationScope(d int) [2]int {
+	tokens := js.tokens
+	b := js.enclosing[d]
+	if tokens[d].text == "var" {
+		for ; b >= 0; b = js.enclosing[b] {
+			if tokens[b].text == "{" && js.isFunctionBody(b) {
+				return [2]int{b, js.closeOf[b]}
+			}
+		}
+		return [2]int{0, len(tokens) - 1}
+	}
+	switch {
+	case b < 0:
+		return [2]int{0, len(tokens) - 1}
+	case tokens[b].text == "{":
+		return [2]int{b, js.closeOf[b]}
+	case tokens[b].text == "(" && b > 0 && tokens[b-1].text == "for":
+		if end := statementEnd(tokens, js.closeOf, js.closeOf[b]+1); end > 0 {
+			return [2]int{b, end - 1}
+		}
+	}
+	return js.declarationScope(b)
+}
+
+// isFunctionBody tells if the "{" at index b is the body of a function
+func (js *jsScopes) isFunctionBody(b int) bool {
+	if b == 0 {
+		return false
+	}
+	if js.tokens[b-1].text == "=>" {
+		return true
+	}
+	if js.tokens[b-1].text != ")" {
+		return false
+	}
+	for o := b - 2; o >= 0; o-- {
+		if js.tokens[o].text == "(" && js.closeOf[o] == b-1 {
+			return isJSParams(js.tokens, js.closeOf, o)
+		}
+	}
+	return false
+}
+
+// paramsScope returns the scope of the parameters opened by the "(" at index o: the parameters and the body
+func (js *jsScopes) paramsScope(o int) [2]int {
+	c := js.closeOf[o]
+	switch {
+	case c+1 < len(js.tokens) && js.tokens[c+1].text == "=>":
+		return [2]int{o, js.arrowEnd(c + 1)}
+	case c+1 < len(js.tokens) && js.tokens[c+1].text == "{":
+		return [2]int{o, js.closeOf[c+1]}
+	}
+	return [2]int{o, c}
+}
+
+// arrowEnd returns the index of the last token of the body of the arrow function whose "=>" is at index a
+func (js *jsScopes) arrowEnd(a int) int {
+	if a+1 < len(js.tokens) && js.tokens[a+1].text == "{" {
+		return js.closeOf[a+1]
+	}
+	for j := a + 1; j < len(js.tokens); j++ {
+		if js.tokens[j].kind != jsPunct {
+			continue
+		}
+		switch js.tokens[j].text {
+		case "(", "[", "{":
+			j = js.closeOf[j]
+		case ",", ";", ")", "]", "}":
+			return j - 1
+		}
+	}
+	return len(js.tokens) - 1
+}
+
+// matchMemberExpression tells if the tokens from index k are the names separated by dots,
+// returns the index of the last name
+func matchMemberExpression(tokens []*jsToken, k int, names []string) (int, bool) {
+	for i, name := range names {
+		j := k + 2*i
+		if j >= len(tokens) || tokens[j].kind != jsIdent || tokens[j].text != name {
+			return 0, false
+		}
+		if i > 0 && tokens[j-1].text != "." {
+			return 0, false
+		}
+	}
+	return k + 2*(len(names)-1), true
+}
+
+// matchJSBrackets returns for each opening bracket the index of the closing one, false if brackets are unbalanced
+func matchJSBrackets(tokens []*jsToken) ([]int, bool) {
+	closeOf := make([]int, len(tokens))
+	var stack []int
+	for k, t := range tokens {
+		if t.kind != jsPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			stack = append(stack, k)
+		case ")", "]", "}":
+			if len(stack) == 0 {
+				return nil, false
+			}
+			closeOf[stack[len(stack)-1]] = k
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return closeOf, len(stack) == 0
+}
+
+// statementEnd returns the index following the statement starting at index k, -1 if it is not understood
+// Only blocks, if statements and the simple statements ended by a semicolon are understood.
+func statementEnd(tokens []*jsToken, closeOf []int, k int) int {
+	if k >= len(tokens) {
+		return -1
+	}
+	t := tokens[k]
+	switch {
+	case t.kind == jsPunct && t.text == "{":
+		return closeOf[k] + 1
+	case t.kind == jsIdent && t.text == "if":
+		if k+1 >= len(tokens) || tokens[k+1].text != "(" {
+			return -1
+		}
+		end := statementEnd(tokens, closeOf, closeOf[k+1]+1)
+		if end >= 0 && end < len(tokens) && tokens[end].kind == jsIdent && tokens[end].text == "else" {
+			return statementEnd(tokens, closeOf, end+1)
+		}
+		return end
+	case t.kind == jsIdent && jsReserved[t.text] && t.text != "var" && t.text != "let" && t.text != "const" &&
+		t.text != "return" && t.text != "throw" && t.text != "break" && t.text != "continue" && t.text != "new" &&
+		t.text != "typeof" && t.text != "delete" && t.text != "void" && t.text != "this":
+		return -1
+	}
+	for j := k; j < len(tokens); j++ {
+		if j > k && tokens[j].newlineBefore && !continuesAfter(tokens[j-1]) && !continuesBefore(tokens[j]) {
+			return -1 // ended by a line terminator
+		}
+		if tokens[j].kind != jsPunct {
+			continue
+		}
+		switch tokens[j].text {
+		case "(", "[", "{":
+			j = closeOf[j]
+		case ";":
+			return j + 1
+		case ")", "]", "}":
+			return -1
+		}
+	}
+	return -1
+}
+
+// emptyStatement returns the replacement of a statement following prev: a lone semicolon when it is
+// the body of a statement (ie: "else") or a labelled statement, nothing otherwise
+func emptyStatement(prev *jsToken) string {
+	if prev != nil && (prev.text == ")" || prev.text == "else" || prev.text == "do" || prev.text == ":") {
+		return ";"
+	}
+	return ""
+}
+
+// stripDeadBranches finds the if statements with a constant condition and removes their dead branch
+// The kept branches are searched too, so the nested dead branches are removed as well.
+func stripDeadBranches(tokens []*jsToken, closeOf []int) []jsEdit {
+	var edits []jsEdit
+	// the ranges of tokens removed, which are not searched
+	var removed [][2]int
+	isRemoved := func(k int) bool {
+		for _, r := range removed {
+			if k >= r[0] && k < r[1] {
+				return true
+			}
+		}
+		return false
+	}
+	for k := 0; k < len(tokens); k++ {
+		t := tokens[k]
+		if isRemoved(k) || t.kind != jsIdent || t.text != "if" || isMemberName(tokens, k) || k+1 >= len(tokens) || tokens[k+1].text != "(" {
+			continue
+		}
+		condClose := closeOf[k+1]
+		value, ok := (&jsEvaluator{tokens[k+2 : condClose], 0}).evaluate()
+		if !ok {
+			continue
+		}
+		consequentEnd := statementEnd(tokens, closeOf, condClose+1)
+		if consequentEnd < 0 {
+			continue
+		}
+		alternativeEnd := consequentEnd
+		hasElse := consequentEnd < len(tokens) && tokens[consequentEnd].kind == jsIdent && tokens[consequentEnd].text == "else"
+		if hasElse {
+			if alternativeEnd = statementEnd(tokens, closeOf, consequentEnd+1); alternativeEnd < 0 {
+				continue
+			}
+		}
+		var prev *jsToken
+		if k > 0 {
+			prev = tokens[k-1]
+		}
+		switch {
+		case jsTruthy(value):
+			edits = append(edits, jsEdit{t.start, tokens[condClose+1].start, ""})
+			if hasElse {
+				edits = append(edits, jsEdit{tokens[consequentEnd].start, tokenEnd(tokens[alternativeEnd-1]), ""})
+				removed = append(removed, [2]int{consequentEnd, alternativeEnd})
+			}
+		case hasElse:
+			edits = append(edits, jsEdit{t.start, tokens[consequentEnd+1].start, ""})
+			removed = append(removed, [2]int{k, consequentEnd + 1})
+		default:
+			edits = append(edits, jsEdit{t.start, tokenEnd(tokens[consequentEnd-1]), emptyStatement(prev)})
+			removed = append(removed, [2]int{k, consequentEnd})
+		}
+	}
+	// the edits of the kept consequents come after the removal of their else
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	return edits
+}
+
+// dropConsole finds the console.* call and debugger statements
+func dropConsole(tokens []*jsToken, closeOf []int) []jsEdit {
+	var edits []jsEdit
+	for k := 0; k < len(tokens); k++ {
+		t := tokens[k]
+		if t.kind != jsIdent || t.text != "console" && t.text != "debugger" || isMemberName(tokens, k) {
+			continue
+		}
+		var prev *jsToken
+		if k > 0 {
+			prev = tokens[k-1]
+		}
+		// only statements
+		if prev != nil && !t.newlineBefore && (prev.kind != jsPunct || !strings.Contains(";{})", prev.text)) && prev.text != "else" {
+			continue
+		}
+		end := k
+		if t.text == "console" {
+			for end+2 < len(tokens) && tokens[end+1].text == "." && tokens[end+2].kind == jsIdent {
+				end += 2
+			}
+			if end == k || end+1 >= len(tokens) || tokens[end+1].text != "(" {
+				continue
+			}
+			end = closeOf[end+1]
+		}
+		switch {
+		case end+1 < len(tokens) && tokens[end+1].text == ";":
+			end++
+		case end+1 < len(tokens) && !tokens[end+1].newlineBefore && tokens[end+1].text != "}":
+			continue
+		}
+		edits = append(edits, jsEdit{t.start, tokenEnd(tokens[end]), emptyStatement(prev)})
+		k = end
+	}
+	return edits
+}
+
+// applyJSEdits applies the sorted and non overlapping edits to src
+// The lines left empty by a removal are removed too.
+func applyJSEdits(src []byte, edits []jsEdit) []byte {
+	if len(edits) == 0 {
+		return src
+	}
+	var buf bytes.Buffer
+	last := 0
+	for _, edit := range edits {
+		start, end := edit.start, edit.end
+		if len(edit.text) == 0 {
+			lineStart := start
+			for lineStart > last && (src[lineStart-1] == ' ' || src[lineStart-1] == '\t') {
+				lineStart--
+			}
+			lineEnd := end
+			for lineEnd < len(src) && (src[lineEnd] == ' ' || src[lineEnd] == '\t' || src[lineEnd] == '\r') {
+				lineEnd++
+			}
+			if lineEnd == len(src) || src[lineEnd] == '\n' {
+				// the spaces left at the end of the line go too, and the line if it is empty
+				start = lineStart
+				if lineStart == 0 || src[lineStart-1] == '\n' {
+					if end = lineEnd; end < len(src) {
+						end++
+					}
+				}
+			}
+		}
+		buf.Write(src[last:start])
+		buf.WriteString(edit.text)
+		last = end
+	}
+	buf.Write(src[last:])
+	return buf.Bytes()
+}
+
+// jsTruthy tells if a constant value is truthy in javascript
+func jsTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0 && v == v
+	case string:
+		return len(v) > 0
+	}
+	return false // null and undefined
+}
+
+// jsEvaluator evaluates the conditions made of literals, "!", "===", "!==", "==", "!=", "&&", "||" and parenthesis
+type jsEvaluator struct {
+	tokens []*jsToken
+	pos    int
+}
+
+// evaluate returns the value of the expression, false if it is not constant
+func (e *jsEvaluator) evaluate() (interface{}, bool) {
+	value, ok := e.or()
+	return value, ok && e.pos == len(e.tokens)
+}
+
+func (e *jsEvaluator) accept(texts ...string) string {
+	if e.pos < len(e.tokens) && e.tokens[e.pos].kind == jsPunct {
+		for _, text := range texts {
+			if e.tokens[e.pos].text == text {
+				e.pos++
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+func (e *jsEvaluator) or() (interface{}, bool) {
+	left, ok := e.and()
+	for ok && e.accept("||") != "" {
+		var right interface{}
+		if right, ok = e.and(); ok && !jsTruthy(left) {
+			left = right
+		}
+	}
+	return left, ok
+}
+
+func (e *jsEvaluator) and() (interface{}, bool) {
+	left, ok := e.equality()
+	for ok && e.accept("&&") != "" {
+		var right interface{}
+		if right, ok = e.equality(); ok && jsTruthy(left) {
+			left = right
+		}
+	}
+	return left, ok
+}
+
+func (e *jsEvaluator) equality() (interface{}, bool) {
+	left, ok := e.unary()
+	for ok {
+		op := e.accept("===", "!==", "==", "!=")
+		if op == "" {
+			break
+		}
+		var right interface{}
+		if right, ok = e.unary(); !ok {
+			break
+		}
+		var equal bool
+		switch {
+		case left == right:
+			equal = true
+		case op == "==" || op == "!=":
+			leftNullish, rightNullish := isJSNullish(left), isJSNullish(right)
+			if leftNullish || rightNullish {
+				equal = leftNullish && rightNullish
+			} else if !sameJSType(left, right) {
+				return nil, false // the other type conversions are not evaluated
+			}
+		}
+		left = equal == (op == "===" || op == "==")
+	}
+	return left, ok
+}
+
+func isJSNullish(value interface{}) bool {
+	_, undefined := value.(jsUndefined)
+	return value == nil || undefined
+}
+
+func sameJSType(a, b interface{}) bool {
+	switch a.(type) {
+	case bool:
+		_, ok := b.(bool)
+		return ok
+	case float64:
+		_, ok := b.(float64)
+		return ok
+	case string:
+		_, ok := b.(string)
+		return ok
+	}
+	return false
+}
+
+func (e *jsEvaluator) unary() (interface{}, bool) {
+	if e.accept("!") != "" {
+		value, ok := e.unary()
+		return !jsTruthy(value), ok
+	}
+	if e.accept("(") != "" {
+		value, ok := e.or()
+		return value, ok && e.accept(")") != ""
+	}
+	if e.pos >= len(e.tokens) {
+		return nil, false
+	}
+	t := e.tokens[e.pos]
+	e.pos++
+	switch t.kind {
+	case jsIdent:
+		switch t.text {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		case "null":
+			return nil, true
+		case "undefined":
+			return jsUndefined{}, true
+		}
+	case jsNumber:
+		value, err := strconv.ParseFloat(t.text, 64)
+		return value, err == nil
+	case jsString:
+		if !strings.Contains(t.text, "\\") {
+			return t.text[1 : len(t.text)-1], true
+		}
+	}
+	return nil, false
+}
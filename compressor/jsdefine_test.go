@@ -0,0 +1,84 @@
+package compressor
+
+import "testing"
+
+func TestJSDefiner(t *testing.T) {
+	defines := map[string]interface{}{"__DEV__": false, "process.env.NODE_ENV": "production"}
+	tests := []struct {
+		name        string
+		src         string
+		dropConsole bool
+		want        string
+	}{
+		// defines
+		{"expression", "var a = __DEV__ ? 1 : 2;", false, "var a = false ? 1 : 2;"},
+		{"member expression", "env = process.env.NODE_ENV;", false, `env = "production";`},
+		{"call argument", "f(a, __DEV__, b);", false, "f(a, false, b);"},
+		{"array element", "x = [a, __DEV__];", false, "x = [a, false];"},
+		{"property value", "var o = { __DEV__: __DEV__, b: 1 };", false, "var o = { __DEV__: false, b: 1 };"},
+		{"template substitution", "`${__DEV__}`", false, "`${false}`"},
+		{"property access", "o.__DEV__ = 1;", false, "o.__DEV__ = 1;"},
+		{"assignment", "__DEV__ = 2;", false, "__DEV__ = 2;"},
+		{"declaration", "var __DEV__ = 1;", false, "var __DEV__ = 1;"},
+		{"function parameter", "function f(__DEV__) { return __DEV__; }", false, "function f(__DEV__) { return __DEV__; }"},
+		{"parameter default", "function f(a, __DEV__ = __DEV__) {}", false, "function f(a, __DEV__ = __DEV__) {}"},
+		{"default of another parameter", "function f(a = __DEV__) { return a; }", false, "function f(a = false) { return a; }"},
+		{"arrow parameters", "var f = (__DEV__) => __DEV__;", false, "var f = (__DEV__) => __DEV__;"},
+		{"arrow parameter body", "var f = [__DEV__ => __DEV__, __DEV__];", false, "var f = [__DEV__ => __DEV__, false];"},
+		{"after the function", "function f(__DEV__) { return __DEV__; }\nx = __DEV__;", false,
+			"function f(__DEV__) { return __DEV__; }\nx = false;"},
+		{"destructured declaration", "const {__DEV__} = obj; __DEV__;", false, "const {__DEV__} = obj; __DEV__;"},
+		{"block declaration", "{ let __DEV__ = 1; a(__DEV__); }\nb(__DEV__);", false, "{ let __DEV__ = 1; a(__DEV__); }\nb(false);"},
+		{"var hoisted to the function", "function f() { if (x) { var __DEV__ = 1; } return __DEV__; }\nb(__DEV__);", false,
+			"function f() { if (x) { var __DEV__ = 1; } return __DEV__; }\nb(false);"},
+		{"for declaration", "for (let __DEV__ of l) a(__DEV__);\nb(__DEV__);", false, "for (let __DEV__ of l) a(__DEV__);\nb(false);"},
+		{"catch parameter reference", "try {} catch (__DEV__) { a(__DEV__); }", false, "try {} catch (__DEV__) { a(__DEV__); }"},
+		{"shadowed member expression root", "function f(process) { return process.env.NODE_ENV; }", false,
+			"function f(process) { return process.env.NODE_ENV; }"},
+		{"destructuring assignment", "({ a: __DEV__ } = o); b(__DEV__);", false, "({ a: __DEV__ } = o); b(false);"},
+		{"arrow parameter", "var f = __DEV__ => 1;", false, "var f = __DEV__ => 1;"},
+		{"method parameter", "o = { m(__DEV__) { return 1; } };", false, "o = { m(__DEV__) { return 1; } };"},
+		{"catch parameter", "try {} catch (__DEV__) {}", false, "try {} catch (__DEV__) {}"},
+		{"shorthand property", "var o = { __DEV__ };", false, "var o = { __DEV__ };"},
+		{"object destructuring", "var { __DEV__ } = config;", false, "var { __DEV__ } = config;"},
+		{"renamed destructuring", "var { a: __DEV__ } = config;", false, "var { a: __DEV__ } = config;"},
+		{"array destructuring", "var [a, __DEV__] = list;", false, "var [a, __DEV__] = list;"},
+		{"destructured parameter", "function f({ a: __DEV__ }) {}", false, "function f({ a: __DEV__ }) {}"},
+
+		// dead branches
+		{"falsy condition", "if (__DEV__) { log(); }\nrun();", false, "run();"},
+		{"truthy condition", "if (!__DEV__) {\n  run();\n}", false, "{\n  run();\n}"},
+		{"falsy condition with else", `if (process.env.NODE_ENV !== "production") { check(); } else { fast(); }`, false, "{ fast(); }"},
+		{"truthy condition with else", "if (!__DEV__) { fast(); } else { check(); }", false, "{ fast(); }"},
+		{"else chain", `if (__DEV__) a(); else if (process.env.NODE_ENV === "test") b(); else c();`, false, "c();"},
+		{"else chain with an unknown condition", "if (__DEV__) { a(); } else if (x) { b(); } else { c(); }", false,
+			"if (x) { b(); } else { c(); }"},
+		{"nested in the kept consequent",
+			"if (process.env.NODE_ENV === \"production\") {\n  if (__DEV__) { a(); } else { b(); }\n} else {\n  c();\n}", false,
+			"{\n  { b(); }\n}"},
+		{"nested in the kept alternative", "if (__DEV__) {\n  a();\n} else {\n  if (__DEV__) b(); else c();\n}", false,
+			"{\n  c();\n}"},
+		{"body of an else", "if (x) a(); else if (__DEV__) b();", false, "if (x) a(); else ;"},
+		{"labelled statement", "label: if (__DEV__) { a() }", false, "label: ;"},
+		{"statement ended by a line terminator", "if (__DEV__) a()\nb()", false, "if (false) a()\nb()"},
+		{"unknown condition", "if (__DEV__ || x) { a(); }", false, "if (false || x) { a(); }"},
+
+		// console
+		{"console calls", "console.log(1);\nfoo();\ndebugger;\nbar()", true, "foo();\nbar()"},
+		{"console call without semicolon", "console.log(1)\nfoo()", true, "foo()"},
+		{"console call as a body", "if (x) console.warn(2)\nbar()", true, "if (x) ;\nbar()"},
+		{"console call in an expression", "x = console.log(1);", true, "x = console.log(1);"},
+		{"console kept", "console.log(1);", false, "console.log(1);"},
+	}
+	for _, test := range tests {
+		jd := &JSDefiner{Defines: defines, DropConsole: test.dropConsole}
+		out, err := jd.Process([]byte(test.src), "test.js")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if string(out) != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, out, test.want)
+		}
+	}
+}
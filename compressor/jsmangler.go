@@ -0,0 +1,495 @@
+package compressor
+
+import (
+	"sort"
+	"strings"
+)
+
+// jsReserved are the words that can't be used as variable names
+var jsReserved = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true, "debugger": true,
+	"default": true, "delete": true, "do": true, "else": true, "enum": true, "export": true, "extends": true,
+	"false": true, "finally": true, "for": true, "function": true, "if": true, "implements": true, "import": true,
+	"in": true, "instanceof": true, "interface": true, "let": true, "new": true, "null": true, "package": true,
+	"private": true, "protected": true, "public": true, "return": true, "static": true, "super": true,
+	"switch": true, "this": true, "throw": true, "true": true, "try": true, "typeof": true, "var": true,
+	"void": true, "while": true, "with": true, "yield": true, "await": true,
+}
+
+// identifiers never renamed, even when declared
+var jsNotMangled = map[string]bool{
+	"arguments": true, "eval": true, "async": true, "get": true, "set": true, "of": true, "as": true, "from": true,
+}
+
+// keywords after which a "{" opens an object literal or a pattern
+var jsObjectKeywords = map[string]bool{
+	"return": true, "typeof": true, "void": true, "delete": true, "in": true, "of": true, "instanceof": true,
+	"new": true, "case": true, "yield": true, "await": true, "throw": true, "var": true, "let": true, "const": true,
+	"extends": true,
+}
+
+const (
+	jsNameFirstChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_$"
+	jsNameChars      = jsNameFirstChars + "0123456789"
+)
+
+// jsScope is the scope of a function (or a method, or an arrow function with a body)
+type jsScope struct {
+	start, end   int // index of the first and last tokens of the scope
+	paramsOpen   int // index of the "(" of the parameters, -1 if none
+	paramsClose  int
+	singleParam  int // index of the parameter of an arrow function without parenthesis, -1 if none
+	bodyOpen     int // index of the "{" of the body
+	keyword      int // index of the function keyword of a declaration, -1 if none
+	declaredName int // index of the name of a function declaration (declared in the enclosing scope), -1 if none
+	parent       *jsScope
+	decls        map[string]bool
+}
+
+type jsMangler struct {
+	tokens    []*jsToken
+	closeOf   []int  // for each opening bracket, the index of the closing one
+	openOf    []int  // for each closing bracket, the index of the opening one
+	enclosing []int  // for each token, the index of the innermost bracket opened around it, -1 if none
+	object    []bool // for each "{", if it opens an object literal or a pattern (not a block)
+	scopes    []*jsScope
+	scopeOf   []*jsScope // for each token, its innermost scope, nil at top level
+	unsafe    map[string]bool
+}
+
+// mangleJS renames the local variables of the functions found in tokens.
+// It is conservative: a name is renamed only if all its occurrences in the outermost function
+// refer to a variable declared in that function, and functions using eval, with or classes are left untouched.
+func mangleJS(all []*jsToken) {
+	m := &jsMangler{unsafe: make(map[string]bool)}
+	for _, t := range all {
+		if t.kind != jsComment {
+			m.tokens = append(m.tokens, t)
+		}
+	}
+	if !m.matchBrackets() {
+		return
+	}
+	m.classifyBraces()
+	m.findScopes()
+	m.findDeclarations()
+	m.rename(m.findOccurrences())
+}
+
+func isJSOpener(t *jsToken) bool {
+	return t.kind == jsPunct && (t.text == "(" || t.text == "[" || t.text == "{") || t.kind == jsTemplate && strings.HasSuffix(t.text, "${")
+}
+
+func isJSCloser(t *jsToken) bool {
+	return t.kind == jsPunct && (t.text == ")" || t.text == "]" || t.text == "}") || t.kind == jsTemplate && strings.HasPrefix(t.text, "}")
+}
+
+func (m *jsMangler) is(k int, kind jsTokenKind, text string) bool {
+	return k >= 0 && k < len(m.tokens) && m.tokens[k].kind == kind && m.tokens[k].text == text
+}
+
+// matchBrackets fills closeOf, openOf and enclosing, returns false if brackets are unbalanced
+func (m *jsMangler) matchBrackets() bool {
+	pairs := map[string]string{")": "(", "]": "[", "}": "{"}
+	n := len(m.tokens)
+	m.closeOf = make([]int, n)
+	m.openOf = make([]int, n)
+	m.enclosing = make([]int, n)
+	var stack []int
+	for i, t := range m.tokens {
+		m.closeOf[i] = -1
+		m.openOf[i] = -1
+		if isJSCloser(t) {
+			if len(stack) == 0 {
+				return false
+			}
+			open := m.tokens[stack[len(stack)-1]]
+			if t.kind != open.kind || t.kind == jsPunct && pairs[t.text] != open.text {
+				return false
+			}
+			m.openOf[i] = stack[len(stack)-1]
+			m.closeOf[stack[len(stack)-1]] = i
+			stack = stack[:len(stack)-1]
+		}
+		m.enclosing[i] = -1
+		if len(stack) > 0 {
+			m.enclosing[i] = stack[len(stack)-1]
+		}
+		if isJSOpener(t) {
+			stack = append(stack, i)
+		}
+	}
+	return len(stack) == 0
+}
+
+func (m *jsMangler) classifyBraces() {
+	m.object = make([]bool, len(m.tokens))
+	for i := range m.tokens {
+		if m.is(i, jsPunct, "{") {
+			m.object[i] = m.isObjectBrace(i)
+		}
+	}
+}
+
+func (m *jsMangler) inObject(k int) bool {
+	enc := m.enclosing[k]
+	return enc >= 0 && m.object[enc]
+}
+
+// isObjectBrace tells if the "{" at index o opens an object literal or a pattern
+func (m *jsMangler) isObjectBrace(o int) bool {
+	if o == 0 {
+		return false
+	}
+	prev := m.tokens[o-1]
+	switch prev.kind {
+	case jsPunct:
+		switch prev.text {
+		case ")", "]", "}", ";", "{", "=>":
+			return false
+		case ":":
+			return m.isValueColon(o - 1)
+		}
+		return true
+	case jsIdent:
+		return jsObjectKeywords[prev.text]
+	case jsTemplate:
+		return strings.HasSuffix(prev.text, "${")
+	}
+	return false
+}
+
+// isValueColon tells if the ":" at index c is followed by a value (object literal or conditional)
+// and not by a statement (label or case)
+func (m *jsMangler) isValueColon(c int) bool {
+	if m.inObject(c) {
+		return true
+	}
+	enc := m.enclosing[c]
+	for j := c - 1; j > enc; j-- {
+		t := m.tokens[j]
+		switch {
+		case isJSCloser(t):
+			j = m.openOf[j]
+		case t.kind == jsPunct && t.text == "?":
+			return true
+		case t.kind == jsPunct && t.text == ";", t.kind == jsIdent && (t.text == "case" || t.text == "default"):
+			return false
+		}
+	}
+	return false
+}
+
+// isFunctionExpression tells if the function keyword at index k starts an expression (not a declaration)
+func (m *jsMangler) isFunctionExpression(k int) bool {
+	p := k - 1
+	if m.is(p, jsIdent, "async") {
+		p--
+	}
+	if p < 0 {
+		return false
+	}
+	prev := m.tokens[p]
+	if m.tokens[p+1].newlineBefore && !continuesAfter(prev) {
+		return false
+	}
+	switch prev.kind {
+	case jsPunct:
+		return prev.text != ";" && prev.text != "{" && prev.text != "}" && prev.text != ")"
+	case jsIdent:
+		return jsObjectKeywords[prev.text]
+	case jsTemplate:
+		return strings.HasSuffix(prev.text, "${")
+	}
+	return false
+}
+
+// isKeyPrefix tells if the token at index k can be followed by a key in an object literal
+func (m *jsMangler) isKeyPrefix(k int) bool {
+	t := m.tokens[k]
+	if t.kind == jsPunct {
+		return t.text == "{" || t.text == "," || t.text == "*"
+	}
+	return t.kind == jsIdent && (t.text == "get" || t.text == "set" || t.text == "async" || t.text == "static")
+}
+
+func (m *jsMangler) findScopes() {
+	n := len(m.tokens)
+	for i, t := range m.tokens {
+		scope := &jsScope{paramsOpen: -1, singleParam: -1, keyword: -1, declaredName: -1, decls: make(map[string]bool)}
+		switch {
+		case t.kind == jsIdent && t.text == "function":
+			j := i + 1
+			if m.is(j, jsPunct, "*") {
+				j++
+			}
+			nameIdx := -1
+			if j < n && m.tokens[j].kind == jsIdent {
+				nameIdx = j
+				j++
+			}
+			if !m.is(j, jsPunct, "(") || !m.is(m.closeOf[j]+1, jsPunct, "{") {
+				continue
+			}
+			scope.start, scope.paramsOpen, scope.paramsClose = j, j, m.closeOf[j]
+			if nameIdx >= 0 && m.isFunctionExpression(i) {
+				scope.start = nameIdx
+				scope.decls[m.tokens[nameIdx].text] = true
+			} else if nameIdx >= 0 {
+				scope.keyword, scope.declaredName = i, nameIdx
+			}
+		case t.kind == jsPunct && t.text == "(" && i >= 2 && m.tokens[i-1].kind == jsIdent && m.inObject(i-1) && m.isKeyPrefix(i-2):
+			if !m.is(m.closeOf[i]+1, jsPunct, "{") {
+				continue
+			}
+			scope.start, scope.paramsOpen, scope.paramsClose = i, i, m.closeOf[i]
+		case t.kind == jsPunct && t.text == "=>" && i >= 1 && m.is(i+1, jsPunct, "{"):
+			if m.is(i-1, jsPunct, ")") {
+				scope.start, scope.paramsOpen, scope.paramsClose = m.openOf[i-1], m.openOf[i-1], i-1
+			} else if m.tokens[i-1].kind == jsIdent {
+				scope.start, scope.singleParam = i-1, i-1
+			} else {
+				continue
+			}
+		default:
+			continue
+		}
+		if t.text == "=>" {
+			scope.bodyOpen = i + 1
+		} else {
+			scope.bodyOpen = scope.paramsClose + 1
+		}
+		scope.end = m.closeOf[scope.bodyOpen]
+		m.scopes = append(m.scopes, scope)
+	}
+	sort.SliceStable(m.scopes, func(i, j int) bool {
+		return m.scopes[i].start < m.scopes[j].start || m.scopes[i].start == m.scopes[j].start && m.scopes[i].end > m.scopes[j].end
+	})
+	m.scopeOf = make([]*jsScope, n)
+	var stack []*jsScope
+	next := 0
+	for k := range m.tokens {
+		for len(stack) > 0 && stack[len(stack)-1].end < k {
+			stack = stack[:len(stack)-1]
+		}
+		for next < len(m.scopes) && m.scopes[next].start == k {
+			if len(stack) > 0 {
+				m.scopes[next].parent = stack[len(stack)-1]
+			}
+			stack = append(stack, m.scopes[next])
+			next++
+		}
+		if len(stack) > 0 {
+			m.scopeOf[k] = stack[len(stack)-1]
+		}
+	}
+}
+
+// markUnsafe marks all the identifiers from index from to index to (excluded) as never renamed
+func (m *jsMangler) markUnsafe(from, to int) {
+	for k := from; k < to; k++ {
+		if m.tokens[k].kind == jsIdent {
+			m.unsafe[m.tokens[k].text] = true
+		}
+	}
+}
+
+// declareParams declares the parameters of a scope if they are only simple names
+func (m *jsMangler) declareParams(scope *jsScope) {
+	var names []string
+	for k := scope.paramsOpen + 1; k < scope.paramsClose; k++ {
+		if m.is(k, jsPunct, "...") {
+			k++
+		}
+		if k >= scope.paramsClose || m.tokens[k].kind != jsIdent || k+1 < scope.paramsClose && !m.is(k+1, jsPunct, ",") {
+			m.markUnsafe(scope.paramsOpen+1, scope.paramsClose)
+			return
+		}
+		names = append(names, m.tokens[k].text)
+		k++
+	}
+	for _, name := range names {
+		scope.decls[name] = true
+	}
+}
+
+// declarators returns the names declared by the var, let or const at index k
+// the names of destructuring patterns are marked as unsafe
+func (m *jsMangler) declarators(k int) (names []string) {
+	n := len(m.tokens)
+	j := k + 1
+	for j < n {
+		switch t := m.tokens[j]; {
+		case t.kind == jsPunct && (t.text == "{" || t.text == "["):
+			m.markUnsafe(j, m.closeOf[j])
+			j = m.closeOf[j] + 1
+		case t.kind == jsIdent && !jsReserved[t.text]:
+			names = append(names, t.text)
+			j++
+		default:
+			return
+		}
+		if m.is(j, jsPunct, "=") {
+			start := j + 1
+			for j = start; j < n; j++ {
+				t := m.tokens[j]
+				if isJSOpener(t) && !isJSCloser(t) {
+					for j = m.closeOf[j]; isJSOpener(m.tokens[j]); j = m.closeOf[j] {
+					}
+					continue
+				}
+				if isJSCloser(t) || t.kind == jsPunct && (t.text == "," || t.text == ";") {
+					break
+				}
+				if j > start && t.newlineBefore && newlineNeeded(m.tokens[j-1], t) {
+					break
+				}
+			}
+		}
+		if !m.is(j, jsPunct, ",") {
+			return
+		}
+		j++
+	}
+	return
+}
+
+func (m *jsMangler) findDeclarations() {
+	for _, scope := range m.scopes {
+		if scope.paramsOpen >= 0 {
+			m.declareParams(scope)
+		} else {
+			scope.decls[m.tokens[scope.singleParam].text] = true
+		}
+		if scope.declaredName >= 0 {
+			if outer := m.scopeOf[scope.keyword]; outer != nil && m.enclosing[scope.keyword] == outer.bodyOpen {
+				outer.decls[m.tokens[scope.declaredName].text] = true
+			}
+		}
+	}
+	for k, t := range m.tokens {
+		if t.kind != jsIdent || t.text != "var" && t.text != "let" && t.text != "const" {
+			continue
+		}
+		names := m.declarators(k)
+		scope := m.scopeOf[k]
+		if scope == nil || t.text != "var" && m.enclosing[k] != scope.bodyOpen {
+			continue
+		}
+		for _, name := range names {
+			scope.decls[name] = true
+		}
+	}
+}
+
+// findOccurrences returns the indexes of the tokens referring to a variable, by name
+func (m *jsMangler) findOccurrences() map[string][]int {
+	occurrences := make(map[string][]int)
+	n := len(m.tokens)
+	for k, t := range m.tokens {
+		if t.kind != jsIdent || jsReserved[t.text] {
+			continue
+		}
+		if k > 0 && (m.is(k-1, jsPunct, ".") || m.is(k-1, jsPunct, "?.") || m.is(k-1, jsPunct, "#")) {
+			continue
+		}
+		if k > 0 && k+1 < n && m.inObject(k) && m.isKeyPrefix(k-1) {
+			next := m.tokens[k+1]
+			switch {
+			case next.kind == jsPunct && (next.text == ":" || next.text == "("):
+				continue
+			case next.kind == jsPunct && (next.text == "," || next.text == "}" || next.text == "="):
+				// shorthand property, renaming it would change the key
+				m.unsafe[t.text] = true
+			default:
+				// get, set, async or static modifier
+				m.unsafe[t.text] = true
+				continue
+			}
+		}
+		occurrences[t.text] = append(occurrences[t.text], k)
+	}
+	return occurrences
+}
+
+// jsName returns the i-th short variable name
+func jsName(i int) string {
+	name := string(jsNameFirstChars[i%len(jsNameFirstChars)])
+	for i /= len(jsNameFirstChars); i > 0; i /= len(jsNameChars) {
+		i--
+		name += string(jsNameChars[i%len(jsNameChars)])
+	}
+	return name
+}
+
+func (m *jsMangler) rename(occurrences map[string][]int) {
+	for _, top := range m.scopes {
+		if top.parent != nil {
+			continue
+		}
+		used := make(map[string]bool)
+		for k := top.start; k <= top.end; k++ {
+			if m.tokens[k].kind == jsIdent {
+				used[m.tokens[k].text] = true
+			}
+		}
+		if used["eval"] || used["with"] || used["class"] {
+			continue
+		}
+		candidates := make(map[string]bool)
+		for _, scope := range m.scopes {
+			if scope.start < top.start || scope.end > top.end {
+				continue
+			}
+			for name := range scope.decls {
+				if !m.unsafe[name] && !jsNotMangled[name] && !jsReserved[name] {
+					candidates[name] = true
+				}
+			}
+		}
+		counts := make(map[string]int)
+		var names []string
+		for name := range candidates {
+			if indexes, ok := m.localOccurrences(top, name, occurrences[name]); ok {
+				counts[name] = len(indexes)
+				names = append(names, name)
+			}
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return counts[names[i]] > counts[names[j]] || counts[names[i]] == counts[names[j]] && names[i] < names[j]
+		})
+		i := 0
+		for _, name := range names {
+			newName := jsName(i)
+			for used[newName] || jsReserved[newName] {
+				i++
+				newName = jsName(i)
+			}
+			i++
+			used[newName] = true
+			indexes, _ := m.localOccurrences(top, name, occurrences[name])
+			for _, k := range indexes {
+				m.tokens[k].text = newName
+			}
+		}
+	}
+}
+
+// localOccurrences returns the occurrences of name in the scope top
+// ok is false if one of them does not refer to a variable declared in top or its inner scopes
+func (m *jsMangler) localOccurrences(top *jsScope, name string, all []int) (indexes []int, ok bool) {
+	for _, k := range all {
+		if k < top.start || k > top.end {
+			continue
+		}
+		declared := false
+		for scope := m.scopeOf[k]; scope != nil && !declared; scope = scope.parent {
+			declared = scope.decls[name]
+		}
+		if !declared {
+			return nil, false
+		}
+		indexes = append(indexes, k)
+	}
+	return indexes, true
+}
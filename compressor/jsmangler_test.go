@@ -0,0 +1,63 @@
+package compressor
+
+import "testing"
+
+func TestJSMangler(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"locals and parameters",
+			"function f(longName, other) { var local = longName + other; return local; }",
+			"function f(b,c){var a=b+c;return a;}"},
+		{"function expression",
+			"(function() { var first = 1, second = 2; return first + second; })()",
+			"(function(){var a=1,b=2;return a+b;})()"},
+		{"arrow function",
+			"var f = (alpha, beta) => { return alpha * beta; };",
+			"var f=(a,b)=>{return a*b;};"},
+		{"globals",
+			"var globalName = 1; function f() { return globalName; }",
+			"var globalName=1;function f(){return globalName;}"},
+		{"property keys",
+			"function f(o) { var key = 1; return {key: key, o: o.key}; }",
+			"function f(b){var a=1;return{key:a,o:b.key};}"},
+		{"shorthand property",
+			"function f() { var k = 1; return {k}; }",
+			"function f(){var k=1;return{k};}"},
+		{"eval",
+			"function f(a) { var longName = 1; eval('longName'); return longName; }",
+			"function f(a){var longName=1;eval('longName');return longName;}"},
+		{"eval in a nested function",
+			"function f() { var longName = 1; return function g() { return eval('longName'); }; }",
+			"function f(){var longName=1;return function g(){return eval('longName');};}"},
+		{"with",
+			"function f(o) { var longName = 1; with (o) { longName = 2; } return longName; }",
+			"function f(o){var longName=1;with(o){longName=2;}return longName;}"},
+	}
+	for _, test := range tests {
+		out, err := (&JSMinifier{Mangle: true}).Process([]byte(test.src), "test.js")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if string(out) != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, out, test.want)
+		}
+	}
+}
+
+func TestJSName(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 5000; i++ {
+		name := jsName(i)
+		if seen[name] {
+			t.Fatalf("jsName(%d) = %q already returned", i, name)
+		}
+		if !isJSIdentStart(name[0]) {
+			t.Errorf("jsName(%d) = %q is not an identifier", i, name)
+		}
+		seen[name] = true
+	}
+}
@@ -0,0 +1,133 @@
+package compressor
+
+import (
+	"bytes"
+	"strings"
+)
+
+// JSMinifier is here to minify javascript bundles
+// it removes whitespaces and comments (except /*! */ ones, kept for licenses)
+// and, if Mangle is true, renames the local variables of the functions with short names.
+// Register it as a compressor to use it in production mode only:
+//
+//	s.RegisterCompressor("application/javascript", &compressor.JSMinifier{Mangle: true})
+type JSMinifier struct {
+	Mangle bool
+}
+
+// Process to implement ContentTreatmentInterface
+func (jm *JSMinifier) Process(content []byte, path string) ([]byte, error) {
+	tokens, err := tokenizeJS(content)
+	if err != nil {
+		return nil, err
+	}
+	if jm.Mangle {
+		mangleJS(tokens)
+	}
+	return writeJS(tokens), nil
+}
+
+// keywords after which a line terminator can't be removed (restricted productions)
+var jsRestrictedKeywords = map[string]bool{
+	"return": true, "throw": true, "break": true, "continue": true, "yield": true, "async": true,
+}
+
+// keywords that can't end an expression
+var jsContinuingKeywords = map[string]bool{
+	"var": true, "let": true, "const": true, "new": true, "typeof": true, "void": true, "delete": true,
+	"in": true, "instanceof": true, "else": true, "do": true, "case": true, "extends": true,
+}
+
+// punctuators that can't continue an expression on a new line
+var jsNotContinuingPunctuators = map[string]bool{
+	"{": true, "!": true, "~": true, "++": true, "--": true, "...": true, "#": true, "@": true,
+}
+
+// continuesAfter tells if the expression must go on after this token
+func continuesAfter(t *jsToken) bool {
+	switch t.kind {
+	case jsPunct:
+		return t.text != ")" && t.text != "]" && t.text != "}" && t.text != "++" && t.text != "--"
+	case jsIdent:
+		return jsContinuingKeywords[t.text]
+	case jsTemplate:
+		return strings.HasSuffix(t.text, "${")
+	}
+	return false
+}
+
+// continuesBefore tells if this token goes on with the previous expression
+func continuesBefore(t *jsToken) bool {
+	switch t.kind {
+	case jsPunct:
+		return !jsNotContinuingPunctuators[t.text]
+	case jsIdent:
+		return t.text == "in" || t.text == "instanceof"
+	case jsTemplate:
+		return true
+	}
+	return false
+}
+
+// newlineNeeded tells if the line terminator between prev and next can't be removed without changing
+// the automatic semicolon insertion
+func newlineNeeded(prev, next *jsToken) bool {
+	if prev.kind == jsIdent && jsRestrictedKeywords[prev.text] {
+		return true
+	}
+	if next.kind == jsPunct && (next.text == "++" || next.text == "--") {
+		return true
+	}
+	return !continuesAfter(prev) && !continuesBefore(next)
+}
+
+// spaceNeeded tells if prev and next would be read as other tokens without a space between them
+func spaceNeeded(prev, next *jsToken) bool {
+	last := prev.text[len(prev.text)-1]
+	first := next.text[0]
+	switch {
+	case isJSIdentPart(last) && isJSIdentPart(first):
+		return true
+	case prev.kind == jsRegexp && isJSIdentPart(first):
+		return true
+	case last == '+' && first == '+', last == '-' && first == '-':
+		return true
+	case last == '/' && (first == '/' || first == '*'):
+		return true
+	case last == '<' && strings.HasPrefix(next.text, "!--"):
+		return true
+	case prev.kind == jsNumber && first == '.':
+		return !strings.ContainsAny(prev.text, ".eExXoObB")
+	}
+	return false
+}
+
+func writeJS(tokens []*jsToken) []byte {
+	var buf bytes.Buffer
+	var prev *jsToken
+	afterComment := false
+	for _, t := range tokens {
+		switch {
+		case t.kind == jsComment:
+			if buf.Len() > 0 && t.newlineBefore {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString(t.text)
+			afterComment = true
+			continue
+		case afterComment:
+			if t.newlineBefore {
+				buf.WriteByte('\n')
+			}
+		case prev == nil:
+		case t.newlineBefore && newlineNeeded(prev, t):
+			buf.WriteByte('\n')
+		case spaceNeeded(prev, t):
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(t.text)
+		prev = t
+		afterComment = false
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,49 @@
+package compressor
+
+import "testing"
+
+func TestJSMinifier(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"whitespaces", "var a = 1 ;\n\nvar b = a + 2 ;", "var a=1;var b=a+2;"},
+		{"comments", "var a = 1 /* c */ + 2 // x\n;", "var a=1+2;"},
+		{"preserved comment", "/*! license */\nvar a = 1;", "/*! license */\nvar a=1;"},
+		{"division", "a = b / c / d;", "a=b/c/d;"},
+		{"regexp", "x = /ab+c/g.test(s);", "x=/ab+c/g.test(s);"},
+		{"regexp after keyword", "return /x/", "return/x/"},
+		{"regexp after if", "if (a) /a'b/.exec(s)", "if(a)/a'b/.exec(s)"},
+		{"regexp after for head", "for (;;) /a\\/b/.test(s)", "for(;;)/a\\/b/.test(s)"},
+		{"regexp flags before identifier", "x = /a/g in o", "x=/a/g in o"},
+		{"template literal", "t = `a ${b + `c ${d}`} e`;", "t=`a ${b+`c ${d}`} e`;"},
+		{"template literal newlines", "t = `a\n  b`", "t=`a\n  b`"},
+		{"asi after return", "function f() {\n  return\n  1\n}", "function f(){return\n1}"},
+		{"asi before increment", "a\n++b", "a\n++b"},
+		{"asi before decrement", "a\n--b", "a\n--b"},
+		{"no asi before parenthesis", "a = b\n(c)", "a=b(c)"},
+		{"no asi before bracket", "a = [1,2]\n[0]", "a=[1,2][0]"},
+		{"asi between statements", "a = 1\nb = 2", "a=1\nb=2"},
+		{"unary operators", "a = b + +c; d = e - -f; g = h+ ++i", "a=b+ +c;d=e- -f;g=h+ ++i"},
+		{"number property", "x = 1 .toString()", "x=1 .toString()"},
+		{"decimal number property", "x = 1.5 .toFixed()", "x=1.5.toFixed()"},
+		{"keywords", "return typeof a", "return typeof a"},
+	}
+	for _, test := range tests {
+		out, err := (&JSMinifier{}).Process([]byte(test.src), "test.js")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if string(out) != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, out, test.want)
+		}
+	}
+}
+
+func TestJSMinifierError(t *testing.T) {
+	if _, err := (&JSMinifier{}).Process([]byte("s = 'abc"), "test.js"); err != errUnterminatedString {
+		t.Errorf("got error %v, want %v", err, errUnterminatedString)
+	}
+}
@@ -0,0 +1,320 @@
+package compressor
+
+import (
+	"errors"
+	"strings"
+)
+
+type jsTokenKind int
+
+const (
+	jsIdent    jsTokenKind = iota // identifiers and keywords
+	jsPunct                       // punctuators
+	jsNumber                      // numeric literals
+	jsString                      // string literals
+	jsTemplate                    // a chunk of a template literal, up to or from a substitution
+	jsRegexp                      // regular expression literals
+	jsComment                     // preserved comments (/*! */ and shebang)
+)
+
+type jsToken struct {
+	kind          jsTokenKind
+	text          string
+	newlineBefore bool
+	start         int // offset of the token in the source
+}
+
+var (
+	errUnterminatedComment  = errors.New("unterminated comment")
+	errUnterminatedString   = errors.New("unterminated string literal")
+	errUnterminatedTemplate = errors.New("unterminated template literal")
+	errUnterminatedRegexp   = errors.New("unterminated regular expression literal")
+)
+
+// jsPunctuators sorted by decreasing length so the first match is the longest one
+var jsPunctuators = []string{
+	">>>=",
+	"...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "<<", ">>", "**",
+}
+
+// keywords after which an expression (so a regular expression) is expected
+var jsExpressionKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true, "delete": true,
+	"void": true, "throw": true, "case": true, "do": true, "else": true, "yield": true, "await": true,
+}
+
+// keywords whose parenthesized head is followed by a statement, so a "/" after it starts a regular expression
+var jsStatementHeads = map[string]bool{"if": true, "while": true, "for": true, "with": true}
+
+func isJSIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' || c == '\\' || c >= 0x80
+}
+
+func isJSIdentPart(c byte) bool {
+	return isJSIdentStart(c) || c >= '0' && c <= '9'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isJSLineTerminator returns the length of the line terminator at src[i:], 0 if there is none
+func isJSLineTerminator(src []byte, i int) int {
+	switch {
+	case src[i] == '\n' || src[i] == '\r':
+		return 1
+	case i+2 < len(src) && src[i] == 0xe2 && src[i+1] == 0x80 && (src[i+2] == 0xa8 || src[i+2] == 0xa9): // U+2028 and U+2029
+		return 3
+	}
+	return 0
+}
+
+// isJSWhitespace returns the length of the whitespace at src[i:], 0 if there is none
+func isJSWhitespace(src []byte, i int) int {
+	switch {
+	case src[i] == ' ' || src[i] == '\t' || src[i] == '\v' || src[i] == '\f':
+		return 1
+	case i+1 < len(src) && src[i] == 0xc2 && src[i+1] == 0xa0: // no-break space
+		return 2
+	case i+2 < len(src) && src[i] == 0xef && src[i+1] == 0xbb && src[i+2] == 0xbf: // byte order mark
+		return 3
+	}
+	return 0
+}
+
+// regexpAllowed tells if a "/" following prev starts a regular expression
+// closesHead tells if prev is the ")" closing the head of an if, while, for or with statement
+func regexpAllowed(prev *jsToken, closesHead bool) bool {
+	if prev == nil {
+		return true
+	}
+	switch prev.kind {
+	case jsIdent:
+		return jsExpressionKeywords[prev.text]
+	case jsPunct:
+		if prev.text == ")" {
+			return closesHead
+		}
+		return prev.text != "]" && prev.text != "++" && prev.text != "--"
+	case jsTemplate:
+		return strings.HasSuffix(prev.text, "${")
+	}
+	return false
+}
+
+// tokenizeJS splits a javascript source into tokens, dropping whitespaces and comments
+// except the /*! */ ones
+func tokenizeJS(src []byte) ([]*jsToken, error) {
+	var tokens []*jsToken
+	var prev *jsToken
+	var templateDepths []int
+	braceDepth := 0
+	// parenHeads tells for each open parenthesis if it starts the head of an if, while, for or with statement
+	var parenHeads []bool
+	closesHead := false
+	newline := false
+	tokenStart := 0
+	push := func(kind jsTokenKind, text string) {
+		prev = &jsToken{kind, text, newline, tokenStart}
+		tokens = append(tokens, prev)
+		newline = false
+		closesHead = false
+	}
+	i := 0
+	if strings.HasPrefix(string(src), "#!") {
+		for i < len(src) && isJSLineTerminator(src, i) == 0 {
+			i++
+		}
+		push(jsComment, string(src[:i]))
+	}
+	for i < len(src) {
+		c := src[i]
+		tokenStart = i
+		if n := isJSLineTerminator(src, i); n > 0 {
+			newline = true
+			i += n
+			continue
+		}
+		if n := isJSWhitespace(src, i); n > 0 {
+			i += n
+			continue
+		}
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && isJSLineTerminator(src, i) == 0 {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(string(src[i+2:]), "*/")
+			if end < 0 {
+				return nil, errUnterminatedComment
+			}
+			comment := string(src[i : i+2+end+2])
+			if strings.HasPrefix(comment, "/*!") {
+				push(jsComment, comment)
+			} else if strings.ContainsAny(comment, "\r\n\u2028\u2029") {
+				newline = true
+			}
+			i += len(comment)
+		case isJSIdentStart(c):
+			start := i
+			for i < len(src) && isJSIdentPart(src[i]) {
+				if src[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			push(jsIdent, string(src[start:i]))
+		case isDigit(c) || c == '.' && i+1 < len(src) && isDigit(src[i+1]):
+			start := i
+			i = scanJSNumber(src, i)
+			push(jsNumber, string(src[start:i]))
+		case c == '"' || c == '\'':
+			end, err := scanJSString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			push(jsString, string(src[i:end]))
+			i = end
+		case c == '`' || c == '}' && len(templateDepths) > 0 && templateDepths[len(templateDepths)-1] == braceDepth:
+			if c == '}' {
+				templateDepths = templateDepths[:len(templateDepths)-1]
+			}
+			end, substitution, err := scanJSTemplate(src, i)
+			if err != nil {
+				return nil, err
+			}
+			if substitution {
+				templateDepths = append(templateDepths, braceDepth)
+			}
+			push(jsTemplate, string(src[i:end]))
+			i = end
+		case c == '/' && regexpAllowed(prev, closesHead):
+			end, err := scanJSRegexp(src, i)
+			if err != nil {
+				return nil, err
+			}
+			push(jsRegexp, string(src[i:end]))
+			i = end
+		default:
+			punct := string(c)
+			for _, p := range jsPunctuators {
+				if strings.HasPrefix(string(src[i:]), p) {
+					punct = p
+					break
+				}
+			}
+			if punct == "?." && i+2 < len(src) && isDigit(src[i+2]) {
+				punct = "?"
+			}
+			head := false
+			switch punct {
+			case "{":
+				braceDepth++
+			case "}":
+				braceDepth--
+			case "(":
+				parenHeads = append(parenHeads, prev != nil && prev.kind == jsIdent && jsStatementHeads[prev.text])
+			case ")":
+				if len(parenHeads) > 0 {
+					head = parenHeads[len(parenHeads)-1]
+					parenHeads = parenHeads[:len(parenHeads)-1]
+				}
+			}
+			push(jsPunct, punct)
+			closesHead = head
+			i += len(punct)
+		}
+	}
+	return tokens, nil
+}
+
+func scanJSNumber(src []byte, i int) int {
+	if src[i] == '0' && i+1 < len(src) && strings.IndexByte("xXoObB", src[i+1]) >= 0 {
+		i += 2
+		for i < len(src) && (isJSIdentPart(src[i])) {
+			i++
+		}
+		return i
+	}
+	for i < len(src) && (isDigit(src[i]) || src[i] == '_') {
+		i++
+	}
+	if i < len(src) && src[i] == '.' {
+		i++
+		for i < len(src) && (isDigit(src[i]) || src[i] == '_') {
+			i++
+		}
+	}
+	if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+		i++
+		if i < len(src) && (src[i] == '+' || src[i] == '-') {
+			i++
+		}
+		for i < len(src) && isDigit(src[i]) {
+			i++
+		}
+	}
+	if i < len(src) && src[i] == 'n' {
+		i++
+	}
+	return i
+}
+
+// scanJSString returns the end of the string literal starting at src[i]
+func scanJSString(src []byte, i int) (int, error) {
+	quote := src[i]
+	for i++; i < len(src); i++ {
+		switch {
+		case src[i] == '\\':
+			i++
+			if i+1 < len(src) && src[i] == '\r' && src[i+1] == '\n' {
+				i++
+			}
+		case src[i] == quote:
+			return i + 1, nil
+		case src[i] == '\n' || src[i] == '\r':
+			return 0, errUnterminatedString
+		}
+	}
+	return 0, errUnterminatedString
+}
+
+// scanJSTemplate returns the end of the template chunk starting at src[i] (a "`" or the "}" ending a substitution)
+// and if that chunk ends with a substitution
+func scanJSTemplate(src []byte, i int) (int, bool, error) {
+	for i++; i < len(src); i++ {
+		switch {
+		case src[i] == '\\':
+			i++
+		case src[i] == '`':
+			return i + 1, false, nil
+		case src[i] == '$' && i+1 < len(src) && src[i+1] == '{':
+			return i + 2, true, nil
+		}
+	}
+	return 0, false, errUnterminatedTemplate
+}
+
+// scanJSRegexp returns the end of the regular expression literal (flags included) starting at src[i]
+func scanJSRegexp(src []byte, i int) (int, error) {
+	inClass := false
+	for i++; i < len(src); i++ {
+		switch {
+		case src[i] == '\\':
+			i++
+		case src[i] == '\n' || src[i] == '\r':
+			return 0, errUnterminatedRegexp
+		case src[i] == '[':
+			inClass = true
+		case src[i] == ']':
+			inClass = false
+		case src[i] == '/' && !inClass:
+			for i++; i < len(src) && isJSIdentPart(src[i]); i++ {
+			}
+			return i, nil
+		}
+	}
+	return 0, errUnterminatedRegexp
+}
@@ -0,0 +1,98 @@
+package compressor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeJS(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		kinds []jsTokenKind
+		texts []string
+	}{
+		{"division", "a = b / c / d;",
+			[]jsTokenKind{jsIdent, jsPunct, jsIdent, jsPunct, jsIdent, jsPunct, jsIdent, jsPunct},
+			[]string{"a", "=", "b", "/", "c", "/", "d", ";"}},
+		{"regexp after operator", "x = /ab+c/g.test(s);",
+			[]jsTokenKind{jsIdent, jsPunct, jsRegexp, jsPunct, jsIdent, jsPunct, jsIdent, jsPunct, jsPunct},
+			[]string{"x", "=", "/ab+c/g", ".", "test", "(", "s", ")", ";"}},
+		{"regexp after keyword", "return /x/",
+			[]jsTokenKind{jsIdent, jsRegexp},
+			[]string{"return", "/x/"}},
+		{"division after postfix increment", "y = a++ / 2",
+			[]jsTokenKind{jsIdent, jsPunct, jsIdent, jsPunct, jsPunct, jsNumber},
+			[]string{"y", "=", "a", "++", "/", "2"}},
+		{"division after parenthesis", "z = (a) / 2 / (b)",
+			[]jsTokenKind{jsIdent, jsPunct, jsPunct, jsIdent, jsPunct, jsPunct, jsNumber, jsPunct, jsPunct, jsIdent, jsPunct},
+			[]string{"z", "=", "(", "a", ")", "/", "2", "/", "(", "b", ")"}},
+		{"regexp after statement head", "if (x) /a'b/.test(y)",
+			[]jsTokenKind{jsIdent, jsPunct, jsIdent, jsPunct, jsRegexp, jsPunct, jsIdent, jsPunct, jsIdent, jsPunct},
+			[]string{"if", "(", "x", ")", "/a'b/", ".", "test", "(", "y", ")"}},
+		{"division after call in statement head", "while (f(a) / 2) /x/.exec(s)",
+			[]jsTokenKind{jsIdent, jsPunct, jsIdent, jsPunct, jsIdent, jsPunct, jsPunct, jsNumber, jsPunct, jsRegexp, jsPunct, jsIdent, jsPunct, jsIdent, jsPunct},
+			[]string{"while", "(", "f", "(", "a", ")", "/", "2", ")", "/x/", ".", "exec", "(", "s", ")"}},
+		{"nested template literals", "t = `a ${b + `c ${d}`} e`;",
+			[]jsTokenKind{jsIdent, jsPunct, jsTemplate, jsIdent, jsPunct, jsTemplate, jsIdent, jsTemplate, jsTemplate, jsPunct},
+			[]string{"t", "=", "`a ${", "b", "+", "`c ${", "d", "}`", "} e`", ";"}},
+		{"strings with escaped quotes", `x = '\'' + "a\""`,
+			[]jsTokenKind{jsIdent, jsPunct, jsString, jsPunct, jsString},
+			[]string{"x", "=", `'\''`, "+", `"a\""`}},
+		{"comments", "var a = 1 /* c */ + 2 // x\n;",
+			[]jsTokenKind{jsIdent, jsIdent, jsPunct, jsNumber, jsPunct, jsNumber, jsPunct},
+			[]string{"var", "a", "=", "1", "+", "2", ";"}},
+		{"preserved comment", "/*! keep */\nvar a;",
+			[]jsTokenKind{jsComment, jsIdent, jsIdent, jsPunct},
+			[]string{"/*! keep */", "var", "a", ";"}},
+		{"longest punctuator", "a >>>= b ?? c",
+			[]jsTokenKind{jsIdent, jsPunct, jsIdent, jsPunct, jsIdent},
+			[]string{"a", ">>>=", "b", "??", "c"}},
+	}
+	for _, test := range tests {
+		tokens, err := tokenizeJS([]byte(test.src))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		var kinds []jsTokenKind
+		var texts []string
+		for _, token := range tokens {
+			kinds = append(kinds, token.kind)
+			texts = append(texts, token.text)
+		}
+		if !reflect.DeepEqual(texts, test.texts) || !reflect.DeepEqual(kinds, test.kinds) {
+			t.Errorf("%s: got %q %v, want %q %v", test.name, texts, kinds, test.texts, test.kinds)
+		}
+	}
+}
+
+func TestTokenizeJSErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err error
+	}{
+		{"s = 'abc", errUnterminatedString},
+		{"s = `abc", errUnterminatedTemplate},
+		{"s = /abc", errUnterminatedRegexp},
+		{"/* abc", errUnterminatedComment},
+	}
+	for _, test := range tests {
+		if _, err := tokenizeJS([]byte(test.src)); err != test.err {
+			t.Errorf("%q: got error %v, want %v", test.src, err, test.err)
+		}
+	}
+}
+
+func TestTokenizeJSNewlines(t *testing.T) {
+	tokens, err := tokenizeJS([]byte("a\n++b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 3 || tokens[0].newlineBefore || !tokens[1].newlineBefore || tokens[2].newlineBefore {
+		t.Errorf("newlineBefore not set on the token following the line terminator only")
+	}
+	if tokens[1].start != 2 || tokens[2].start != 4 {
+		t.Errorf("got starts %d %d, want 2 4", tokens[1].start, tokens[2].start)
+	}
+}
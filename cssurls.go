@@ -0,0 +1,79 @@
+package sprockets
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/znly/go-sprockets/types"
+)
+
+var (
+	cssURLPattern    = regexp.MustCompile(`(?i)(url\(\s*['"]?)([^'")\s]+)(['"]?\s*\))`)
+	cssImportPattern = regexp.MustCompile(`(?i)(@import\s+['"])([^'"]+)(['"])`)
+	cssMimeTypes     = map[string]bool{"text/css": true, "text/x-scss": true, "text/x-sass": true}
+	// the @import of these sources are resolved by their compiler (ie: sass partials), they are not rewritten
+	cssImportingMimeTypes = map[string]bool{"text/x-scss": true, "text/x-sass": true, "text/x-less": true}
+)
+
+// SetCSSURLRewriter enables or disables the rewriting of the relative url() and @import of the stylesheets bundled into an asset
+// They are relative to the directory of each file, they are rewritten relatively to the asset so they still point to the right file.
+// The @import of the scss, sass and less files are left to their compiler.
+// If assets is not nil (see AssetResolver), the references to the files found by the sprocket are rewritten to their url instead
+// (their digest path when they are in the manifest)
+func (s *Sprocket) SetCSSURLRewriter(enabled bool, assets types.AssetResolver) {
+	s.cssURLRewriter = enabled
+	s.cssURLAssets = assets
+}
+
+// rewriteCSSURLs rewrites the references of the file filePath bundled into the asset bundlePath
+func (s *Sprocket) rewriteCSSURLs(filePath, bundlePath, mimeType string, content []byte) []byte {
+	if !s.cssURLRewriter || !cssMimeTypes[mimeType] {
+		return content
+	}
+	bundleDir := path.Dir(s.logicalPath(bundlePath, ""))
+	rewrite := func(pattern *regexp.Regexp, content []byte) []byte {
+		return pattern.ReplaceAllFunc(content, func(match []byte) []byte {
+			groups := pattern.FindSubmatch(match)
+			ref := string(groups[2])
+			if newRef, ok := s.rewriteCSSURL(ref, filePath, bundleDir); ok {
+				return []byte(string(groups[1]) + newRef + string(groups[3]))
+			}
+			return match
+		})
+	}
+	content = rewrite(cssURLPattern, content)
+	if cssImportingMimeTypes[s.getMimeType(filePath)] {
+		return content
+	}
+	return rewrite(cssImportPattern, content)
+}
+
+// rewriteCSSURL returns the reference ref of the file filePath relatively to bundleDir (the logical directory of the asset)
+// false if it must be kept as is (absolute urls, data uris, fragments, interpolations or missing files)
+func (s *Sprocket) rewriteCSSURL(ref, filePath, bundleDir string) (string, bool) {
+	if len(ref) == 0 || strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "#") || strings.ContainsAny(ref, "$") ||
+		strings.Contains(ref, "#{") || strings.Contains(strings.SplitN(ref, "/", 2)[0], ":") {
+		return "", false
+	}
+	refPath, suffix := ref, ""
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		refPath, suffix = ref[:i], ref[i:]
+	}
+	target := filepath.Join(filepath.Dir(filePath), filepath.FromSlash(refPath))
+	if !isFileExist(target) {
+		return "", false
+	}
+	logicalPath := s.logicalPath(target, "")
+	if s.cssURLAssets != nil {
+		if url, err := s.cssURLAssets.AssetPath(logicalPath); err == nil {
+			return url + suffix, true
+		}
+	}
+	relPath, err := filepath.Rel(filepath.FromSlash(bundleDir), filepath.FromSlash(logicalPath))
+	if err != nil {
+		return "", false
+	}
+	return filepath.ToSlash(relPath) + suffix, true
+}
@@ -0,0 +1,59 @@
+package sprockets
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dataURIMimeTypes are the content types of the files which can be inlined (images and fonts)
+var dataURIMimeTypes = map[string]bool{
+	"image/jpeg": true, "image/png": true, "image/gif": true, "image/svg+xml": true, "image/bmp": true,
+	"image/tiff": true, "image/webp": true, "image/x-icon": true, "image/x-tga": true,
+	"font/ttf": true, "font/otf": true, "font/woff": true, "font/woff2": true,
+	"application/vnd.ms-fontobject": true, "application/font-woff": true, "application/x-font-ttf": true,
+}
+
+// SetDataURIInlining will inline the url() of the stylesheets referencing an image or a font smaller than maxSize bytes
+// as a base64 data uri. The files are searched relatively to the asset then through the paths of their extension (ie: images and fonts)
+// and recorded as dependencies of the asset. A maxSize of 0 disables the inlining.
+func (s *Sprocket) SetDataURIInlining(maxSize int64) {
+	s.dataURIMaxSize = maxSize
+}
+
+// inlineDataURIs inlines the small files referenced by the url() of a stylesheet
+// Return the new content and the inlined files
+func (s *Sprocket) inlineDataURIs(assetPath, mimeType string, content []byte) ([]byte, []string) {
+	if s.dataURIMaxSize <= 0 || !cssMimeTypes[mimeType] {
+		return content, nil
+	}
+	var inlined []string
+	content = cssURLPattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		groups := cssURLPattern.FindSubmatch(match)
+		ref := string(groups[2])
+		if len(ref) == 0 || ref[0] == '/' || ref[0] == '#' || filepath.Ext(ref) == "" {
+			return match
+		}
+		filePath, _, err := s.resolvePath(ref, filepath.Dir(assetPath), true)
+		if err != nil {
+			return match
+		}
+		contentType := s.GetContentType(filePath)
+		if !dataURIMimeTypes[strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])] {
+			return match
+		}
+		info, err := os.Stat(filePath)
+		if err != nil || info.Size() > s.dataURIMaxSize {
+			return match
+		}
+		fileContent, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return match
+		}
+		inlined = append(inlined, filePath)
+		return []byte(`url("data:` + contentType + ";base64," + base64.StdEncoding.EncodeToString(fileContent) + `")`)
+	})
+	return content, inlined
+}
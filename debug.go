@@ -0,0 +1,125 @@
+package sprockets
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DebugAsset is one of the files bundled into an asset
+type DebugAsset struct {
+	// Path is the resolved path of the file
+	Path string
+	// LogicalPath is the path to use to request this file alone from the sprocket
+	LogicalPath string
+	// URL is the LogicalPath with the body=1 query, to serve the file without its dependencies
+	URL string
+	// Alias is the alias rule used to find the file ("pattern -> target"), empty if none
+	Alias string
+}
+
+// SetDebug enables or disables the debug mode
+// In debug mode, the template helpers emit one tag per file bundled into an asset
+func (s *Sprocket) SetDebug(enabled bool) {
+	s.debug = enabled
+}
+
+// GetDebugAssets will return the ordered list of the files bundled into an asset
+// Each of them can be served on its own, compiled without its dependencies, with GetAssetBody
+func (s *Sprocket) GetDebugAssets(assetPath string) ([]*DebugAsset, error) {
+	realAssetPath, extInfo, alias, err := s.resolveAliasedPath(assetPath, "", true)
+	if err != nil {
+		return nil, err
+	}
+	usedAliases := map[string]*aliasRule{realAssetPath: alias}
+	dependencyList := []string{realAssetPath}
+	if extInfo.RequirePattern != nil {
+		if dependencyList, _, _, _, err = s.readDependencies(realAssetPath, s.getMimeType(assetPath), extInfo, false, usedAliases); err != nil {
+			return nil, err
+		}
+	}
+	ext := filepath.Ext(assetPath)
+	ret := make([]*DebugAsset, len(dependencyList))
+	for i, path := range dependencyList {
+		logicalPath := s.logicalPath(path, ext)
+		ret[i] = &DebugAsset{
+			Path:        path,
+			LogicalPath: logicalPath,
+			URL:         logicalPath + "?body=1",
+			Alias:       usedAliases[path].String(),
+		}
+	}
+	return ret, nil
+}
+
+// GetAssetBody will return the content of an asset compiled without its requirements
+func (s *Sprocket) GetAssetBody(assetPath string) ([]byte, error) {
+	content, err := s.getAssetBody(assetPath)
+	if compileErr, ok := err.(*CompileError); ok && s.errorOverlay {
+		if overlay := errorOverlay(assetPath, compileErr); overlay != nil {
+			return overlay, nil
+		}
+	}
+	return content, err
+}
+
+func (s *Sprocket) getAssetBody(assetPath string) ([]byte, error) {
+	realAssetPath, extInfo, err := s.resolvePath(assetPath, "", true)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := s.getMimeType(assetPath)
+	_, content, err := s.readAssetWithDependencies(realAssetPath, "", false)
+	if err != nil {
+		return nil, err
+	}
+	if content, err = s.processFile(realAssetPath, mimeType, content); err != nil {
+		return nil, err
+	}
+	content = s.rewriteCSSURLs(realAssetPath, realAssetPath, mimeType, content)
+	content, needsRuntime := s.wrapModule(realAssetPath, content)
+	if needsRuntime {
+		content = append([]byte(commonJSRuntime), content...)
+		content = append(content, s.commonJSRequire(realAssetPath, []string{realAssetPath})...)
+	}
+	if extInfo.BundleCompiler != nil {
+		content, _, err = s.bundle(realAssetPath, extInfo, content)
+		if err != nil {
+			return nil, &CompileError{realAssetPath, err}
+		}
+	}
+	content, _ = s.inlineDataURIs(realAssetPath, mimeType, content)
+	if content, err = s.processBundle(realAssetPath, mimeType, content); err != nil {
+		return nil, err
+	}
+	for _, f := range extInfo.PostCompileContentTreatment {
+		content, err = f.Process(content, realAssetPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+// logicalPath returns the path of realPath relative to its extension search paths
+// with its extension replaced by ext
+func (s *Sprocket) logicalPath(realPath, ext string) string {
+	logicalPath := ""
+	realPath = strings.TrimSuffix(realPath, templateExt)
+	extInfo := s.getExtensionInfoOrDefault(filepath.Ext(realPath))
+	for e := extInfo.Paths.Front(); e != nil; e = e.Next() {
+		if relPath, err := filepath.Rel(e.Value, realPath); err == nil && !strings.HasPrefix(relPath, "..") {
+			logicalPath = relPath
+			break
+		}
+	}
+	for i := 0; len(logicalPath) == 0 && i < len(extInfo.PathResolvers); i++ {
+		logicalPath, _ = extInfo.PathResolvers[i].LogicalPath(realPath)
+	}
+	if len(logicalPath) == 0 {
+		logicalPath, _ = filepath.Rel(s.assetsPath, realPath)
+	}
+	if len(ext) > 0 {
+		logicalPath = strings.TrimSuffix(logicalPath, filepath.Ext(logicalPath)) + ext
+	}
+	return filepath.ToSlash(logicalPath)
+}
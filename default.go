@@ -3,27 +3,75 @@ package sprockets
 import (
 	"path/filepath"
 	"regexp"
+	"runtime"
 
 	"github.com/znly/go-sprockets/bundlecompiler"
 	"github.com/znly/go-sprockets/filecompiler"
+	"github.com/znly/go-sprockets/jsvm"
 	"github.com/znly/go-sprockets/types"
 )
 
+var defaultMimeTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".png":  "image/png",
+	".svg":  "image/svg+xml",
+	".gif":  "image/gif",
+	".bmp":  "image/bmp",
+	".tiff": "image/tiff",
+	".tga":  "image/x-tga",
+	".eot":  "application/vnd.ms-fontobject",
+	".ttf":  "font/ttf",
+	".woff": "font/woff",
+	".es6":  "application/ecmascript-6",
+	".jsx":  "text/jsx",
+	".ts":   "text/typescript",
+	".less": "text/x-less",
+
+	".ejs":      "text/x-ejs",
+	".mustache": "text/x-mustache",
+	".hbs":      "text/x-handlebars-template",
+}
+
+// defaultURLPrefix is the url where NewWithDefault expects the assets to be served, as in rails
+const defaultURLPrefix = "/assets"
+
 //NewWithDefault create a new Sprocket pipeline:
 //- using assetsPath as default asset directory
 //- ".css", ".scss" and ".sass" configuration
 //    - adding filecompiler for sass (to turn it into scss)
-//    - adding bundlecompiler for sass, scss, css
+//    - adding bundlecompiler for sass, scss, css (their asset helpers give urls under /assets)
 //    - adding a search path to [assetsPath]/stylesheets
 //    - adding require rules
+//    - adding mime types
+//- ".less" configuration
+//    - adding bundlecompiler for less with the embedded less.js, once go generate embedded it
+//    - adding it as an alter extension of ".css" when its bundlecompiler is set
+//    - adding require rules
+//    - adding a search path to [assetsPath]/stylesheets
+//    - adding mime types
 //- ".js" and ".coffee" configuration
 //    - adding filecompiler for coffee script (to turn file into )
 //    - adding require rules
 //    - adding a search path to [assetsPath]/javascripts
+//    - adding mime types
+//- ".es6", ".jsx" and ".ts" configuration
+//    - adding filecompiler for es6 and jsx with the embedded babel and for ts with the embedded typescript
+//      (to turn them into ES5), once go generate embedded them
+//    - adding them as alter extensions of ".js" when their filecompiler is set
+//    - adding require rules
+//    - adding a search path to [assetsPath]/javascripts
+//    - adding mime types
+//- ".ejs", ".mustache" and ".hbs" configuration
+//    - adding filecompiler for ejs and mustache (to turn templates into window.JST functions named from [assetsPath]/javascripts)
+//    - adding them as alter extensions of ".js"
+//    - adding a search path to [assetsPath]/javascripts
+//    - adding mime types
 //- ".jpg", ".png", ".svg", ".gif", ".bmp", ".tiff", ".tga" configuration
 //    - adding a search path to [assetsPath]/images
+//    - adding mime types
 //- ".eot", ".svg", ".ttf", ".woff" configuration
 //    - adding a search path to [assetsPath]/fonts
+//    - adding mime types
 func NewWithDefault(assetsPath, publicPath string) (s *Sprocket, err error) {
 	s, err = New(assetsPath, publicPath)
 	if err != nil {
@@ -35,8 +83,10 @@ func NewWithDefault(assetsPath, publicPath string) (s *Sprocket, err error) {
 		Head:    regexp.MustCompile(`(\s*(/\*(.*?\s*?)*\*/)*)*`),
 		Require: regexp.MustCompile(`^\s*(?:\*/)?\s*(?:/\*.*?\*/)*\s*(\*\s*=\s*require((?:_directory|_tree)?)\s+(.+))`),
 	})
-	s.SetBundleCompiler(".css", &bundlecompiler.ScssSassCompiler{})
+	assets := s.AssetResolver(defaultURLPrefix)
+	s.SetBundleCompiler(".css", &bundlecompiler.ScssSassCompiler{Assets: assets})
 	s.PushFrontExtensionPath(".css", filepath.Join(s.assetsPath, "stylesheets"))
+	s.SetMimeType(".css", "text/css", "utf-8")
 
 	s.PushFrontAlterExtension(".scss", ".css")
 	s.PushFrontAlterExtension(".scss", ".sass")
@@ -44,8 +94,10 @@ func NewWithDefault(assetsPath, publicPath string) (s *Sprocket, err error) {
 		Head:    regexp.MustCompile(`(\s*(/\*(.*\s+)*\*/)*([ \t]*//.*\s+)*)*`),
 		Require: regexp.MustCompile(`^\s*(?:\*/)?\s*(?:/\*.*?\*/)*\s*((?:\*|//)\s*=\s*require((?:_directory|_tree)?)\s+(.+))`),
 	})
-	s.SetBundleCompiler(".scss", &bundlecompiler.ScssSassCompiler{})
+	s.SetBundleCompiler(".scss", &bundlecompiler.ScssSassCompiler{Assets: assets})
 	s.PushFrontExtensionPath(".scss", filepath.Join(s.assetsPath, "stylesheets"))
+	s.SetMimeType(".scss", "text/x-scss", "utf-8")
+	s.SetOutputMimeType(".scss", "text/css")
 
 	s.PushFrontAlterExtension(".sass", ".css")
 	s.PushFrontAlterExtension(".sass", ".scss")
@@ -54,8 +106,25 @@ func NewWithDefault(assetsPath, publicPath string) (s *Sprocket, err error) {
 		Require: regexp.MustCompile(`^\s*(?:\*/)?\s*(?:/\*.*?\*/)*\s*((?:\*|//)\s*=\s*require((?:_directory|_tree)?)\s+(.+))`),
 	})
 	s.SetFileCompiler(".sass", &filecompiler.SassCompiler{})
-	s.SetBundleCompiler(".sass", &bundlecompiler.ScssSassCompiler{})
+	s.SetBundleCompiler(".sass", &bundlecompiler.ScssSassCompiler{Assets: assets})
 	s.PushFrontExtensionPath(".sass", filepath.Join(s.assetsPath, "stylesheets"))
+	s.SetMimeType(".sass", "text/x-sass", "utf-8")
+	s.SetOutputMimeType(".sass", "text/css")
+
+	if less, err := bundlecompiler.NewDefaultLessCompiler(runtime.NumCPU()); err == nil {
+		s.SetBundleCompiler(".less", less)
+		s.PushBackAlterExtension(".css", ".less")
+	} else if err != jsvm.ErrNotEmbedded {
+		return nil, err
+	}
+	s.PushFrontAlterExtension(".less", ".css")
+	s.SetRequirePattern(".less", &types.RequirePattern{
+		Head:    regexp.MustCompile(`(\s*(/\*(.*\s+)*\*/)*([ \t]*//.*\s+)*)*`),
+		Require: regexp.MustCompile(`^\s*(?:\*/)?\s*(?:/\*.*?\*/)*\s*((?:\*|//)\s*=\s*require((?:_directory|_tree)?)\s+(.+))`),
+	})
+	s.PushFrontExtensionPath(".less", filepath.Join(s.assetsPath, "stylesheets"))
+	s.SetMimeType(".less", defaultMimeTypes[".less"], "utf-8")
+	s.SetOutputMimeType(".less", "text/css")
 
 	s.PushFrontAlterExtension(".coffee", ".js")
 	s.SetFileCompiler(".coffee", filecompiler.NewCoffeeCompiler())
@@ -64,6 +133,8 @@ func NewWithDefault(assetsPath, publicPath string) (s *Sprocket, err error) {
 		Require: regexp.MustCompile(`^(\s*#\s*=\s*require((?:_directory|_tree)?)\s+(.+))`),
 	})
 	s.PushFrontExtensionPath(".coffee", filepath.Join(s.assetsPath, "javascripts"))
+	s.SetMimeType(".coffee", "text/coffeescript", "utf-8")
+	s.SetOutputMimeType(".coffee", "application/javascript")
 
 	s.PushFrontAlterExtension(".js", ".coffee")
 	s.SetRequirePattern(".js", &types.RequirePattern{
@@ -71,13 +142,58 @@ func NewWithDefault(assetsPath, publicPath string) (s *Sprocket, err error) {
 		Require: regexp.MustCompile(`^\s*(?:\*/)?\s*(?:/\*.*?\*/)*\s*((?:\*|//)\s*=\s*require((?:_directory|_tree)?)\s+(.+))`),
 	})
 	s.PushFrontExtensionPath(".js", filepath.Join(s.assetsPath, "javascripts"))
+	s.SetMimeType(".js", "application/javascript", "utf-8")
+
+	jsCompilers := map[string]types.ContentTreatmentInterface{}
+	if babel, err := filecompiler.NewDefaultBabelCompiler(); err == nil {
+		jsCompilers[".es6"] = babel
+		jsCompilers[".jsx"] = babel
+	} else if err != jsvm.ErrNotEmbedded {
+		return nil, err
+	}
+	if typescript, err := filecompiler.NewDefaultTypeScriptCompiler(runtime.NumCPU()); err == nil {
+		jsCompilers[".ts"] = typescript
+	} else if err != jsvm.ErrNotEmbedded {
+		return nil, err
+	}
+	for _, ext := range []string{".es6", ".jsx", ".ts"} {
+		s.PushFrontAlterExtension(ext, ".js")
+		if compiler, ok := jsCompilers[ext]; ok {
+			s.SetFileCompiler(ext, compiler)
+			s.PushBackAlterExtension(".js", ext)
+		}
+		s.SetRequirePattern(ext, &types.RequirePattern{
+			Head:    regexp.MustCompile(`(\s*(/\*(.*\s+)*\*/)*([ \t]*//.*\s+)*)*`),
+			Require: regexp.MustCompile(`^\s*(?:\*/)?\s*(?:/\*.*?\*/)*\s*((?:\*|//)\s*=\s*require((?:_directory|_tree)?)\s+(.+))`),
+		})
+		s.PushFrontExtensionPath(ext, filepath.Join(s.assetsPath, "javascripts"))
+		s.SetMimeType(ext, defaultMimeTypes[ext], "utf-8")
+		s.SetOutputMimeType(ext, "application/javascript")
+	}
+
+	javascripts := filepath.Join(s.assetsPath, "javascripts")
+	templateCompilers := map[string]types.ContentTreatmentInterface{
+		".ejs":      &filecompiler.EJSCompiler{Root: javascripts},
+		".mustache": &filecompiler.MustacheCompiler{Root: javascripts},
+		".hbs":      &filecompiler.MustacheCompiler{Root: javascripts},
+	}
+	for _, ext := range []string{".ejs", ".mustache", ".hbs"} {
+		s.PushFrontAlterExtension(ext, ".js")
+		s.PushBackAlterExtension(".js", ext)
+		s.SetFileCompiler(ext, templateCompilers[ext])
+		s.PushFrontExtensionPath(ext, javascripts)
+		s.SetMimeType(ext, defaultMimeTypes[ext], "utf-8")
+		s.SetOutputMimeType(ext, "application/javascript")
+	}
 
 	for _, ext := range []string{".jpg", ".png", ".svg", ".gif", ".bmp", ".tiff", ".tga"} {
 		s.PushFrontExtensionPath(ext, filepath.Join(s.assetsPath, "images"))
+		s.SetMimeType(ext, defaultMimeTypes[ext], "")
 	}
 
 	for _, ext := range []string{".eot", ".svg", ".ttf", ".woff"} {
 		s.PushFrontExtensionPath(ext, filepath.Join(s.assetsPath, "fonts"))
+		s.SetMimeType(ext, defaultMimeTypes[ext], "")
 	}
 	return
 }
@@ -0,0 +1,23 @@
+package dependencygraph
+
+import "strings"
+
+// CircularDependencyError is returned by Walk when a node requires itself, directly or not.
+// Cycle holds the paths of the nodes in the cycle in require order, the first node being repeated at the end.
+type CircularDependencyError struct {
+	Cycle []string
+}
+
+func (cde *CircularDependencyError) Error() string {
+	return "CIRCULAR dependencies found: " + strings.Join(cde.Cycle, " -> ")
+}
+
+// newCircularDependencyError build the cycle going from the start Element to the most recently seen one
+func newCircularDependencyError(start *Element) *CircularDependencyError {
+	ret := &CircularDependencyError{}
+	for e := start; e != nil; e = e.Prev() {
+		ret.Cycle = append(ret.Cycle, e.Value.path)
+	}
+	ret.Cycle = append(ret.Cycle, start.Value.path)
+	return ret
+}
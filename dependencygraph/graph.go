@@ -1,7 +1,5 @@
 package dependencygraph
 
-import "errors"
-
 func (g *Graph) addChildToBack(curNode *Node, childName string) {
 	childNode, _ := g.GetOrCreateNode(childName)
 	curNode.edge.PushBack(childNode)
@@ -34,18 +32,19 @@ func (g *Graph) walk(curNode, parentNode *Node, f func(string, string, *Graph) e
 	if err := f(curNode.path, parentPath, g); err != nil {
 		return err
 	}
-	seen.PushFront(curNode)
+	seenElement := seen.PushFront(curNode)
 	for e := curNode.edge.Front(); e != nil; e = e.Next() {
 		val := e.Value
 		if resolved.Find(val) == nil {
-			if seen.Find(val) != nil {
-				return errors.New("CIRCULAR dependencies found with:\nParent: " + val.path + seen.String())
+			if cycleStart := seen.Find(val); cycleStart != nil {
+				return newCircularDependencyError(cycleStart)
 			}
 			if err := g.walk(val, curNode, f, resolved, seen); err != nil {
 				return err
 			}
 		}
 	}
+	seen.Remove(seenElement)
 	resolved.PushBack(curNode)
 	return nil
 }
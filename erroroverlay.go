@@ -0,0 +1,64 @@
+package sprockets
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// SetErrorOverlay enables or disables the error overlay (use it only in development)
+// When enabled, GetAsset will not return a CompileError for ".css" and ".js" assets
+// but an asset displaying the error in the browser:
+//   - a stylesheet showing the error in a body::before banner
+//   - a script showing the error in an overlay and throwing it
+func (s *Sprocket) SetErrorOverlay(enabled bool) {
+	s.errorOverlay = enabled
+}
+
+// errorOverlay returns the overlay content for assetPath or nil if its extension is not handled
+func errorOverlay(assetPath string, compileErr *CompileError) []byte {
+	switch filepath.Ext(assetPath) {
+	case ".css":
+		return []byte(cssErrorOverlay(compileErr.Error()))
+	case ".js":
+		return []byte(jsErrorOverlay(compileErr.Error()))
+	}
+	return nil
+}
+
+var cssStringReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\A `)
+
+func cssErrorOverlay(message string) string {
+	return `body::before {
+  content: "` + cssStringReplacer.Replace(message) + `";
+  display: block;
+  position: relative;
+  z-index: 2147483647;
+  padding: 1em;
+  background: #fff0f0;
+  color: #c00;
+  border-bottom: 3px solid #c00;
+  font: 13px/1.5 monospace;
+  white-space: pre-wrap;
+}
+`
+}
+
+func jsErrorOverlay(message string) string {
+	quotedMessage, _ := json.Marshal(message)
+	return `(function(message) {
+  var show = function() {
+    var overlay = document.createElement("pre");
+    overlay.style.cssText = "position:fixed;top:0;left:0;right:0;bottom:0;margin:0;padding:1em;z-index:2147483647;overflow:auto;background:rgba(255,240,240,0.97);color:#c00;font:13px/1.5 monospace;white-space:pre-wrap;";
+    overlay.appendChild(document.createTextNode(message));
+    document.body.appendChild(overlay);
+  };
+  if (document.body) {
+    show();
+  } else {
+    document.addEventListener("DOMContentLoaded", show);
+  }
+  throw new Error(message);
+})(` + string(quotedMessage) + `);
+`
+}
@@ -3,3 +3,13 @@ package sprockets
 import "errors"
 
 var ErrNotFound = errors.New("Not found")
+
+// CompileError is returned when a FileCompiler or a BundleCompiler failed on an asset
+type CompileError struct {
+	Path string
+	Err  error
+}
+
+func (ce *CompileError) Error() string {
+	return "Compile error in " + ce.Path + ": " + ce.Err.Error()
+}
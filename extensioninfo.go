@@ -1,6 +1,7 @@
 package sprockets
 
 import (
+	"mime"
 	"path/filepath"
 
 	"github.com/znly/go-sprockets/stringlist"
@@ -104,6 +105,13 @@ func (s *Sprocket) AddPostCompileContentTreatment(ext string, PostCompileContent
 	extInfo.PostCompileContentTreatment = append(extInfo.PostCompileContentTreatment, PostCompileContentTreatment)
 }
 
+// AddPathResolver will add a resolver that will be use to find the assets of this extension which are not in its paths
+// (ie: resolver.NodeModulesResolver)
+func (s *Sprocket) AddPathResolver(ext string, resolver types.PathResolver) {
+	extInfo := s.getOrCreateExtensionInfo(ext)
+	extInfo.PathResolvers = append(extInfo.PathResolvers, resolver)
+}
+
 // SetRequirePattern set the require pattern of this extension
 func (s *Sprocket) SetRequirePattern(ext string, rp *types.RequirePattern) {
 	extInfo := s.getOrCreateExtensionInfo(ext)
@@ -121,3 +129,39 @@ func (s *Sprocket) SetFileCompiler(ext string, fileCompiler types.ContentTreatme
 	extInfo := s.getOrCreateExtensionInfo(ext)
 	extInfo.FileCompiler = fileCompiler
 }
+
+// SetMimeType set the content type and the charset (may be empty) of this extension
+func (s *Sprocket) SetMimeType(ext, mimeType, charset string) {
+	extInfo := s.getOrCreateExtensionInfo(ext)
+	extInfo.MimeType = mimeType
+	extInfo.Charset = charset
+}
+
+// SetOutputMimeType set the content type served for this extension when it differs from the mime type of its sources
+// (ie: text/css for .scss), the mime type set with SetMimeType is still the one used to find its processors
+func (s *Sprocket) SetOutputMimeType(ext, mimeType string) {
+	s.getOrCreateExtensionInfo(ext).OutputMimeType = mimeType
+}
+
+// GetContentType will return the content type of an asset base on its extension
+// It uses the output mime type of the extension info, then its mime type, then the mime package, then "application/octet-stream"
+func (s *Sprocket) GetContentType(assetPath string) string {
+	ext := assetExt(assetPath)
+	extInfo := s.getExtensionInfoOrDefault(ext)
+	if len(extInfo.OutputMimeType) > 0 {
+		if len(extInfo.Charset) == 0 {
+			return extInfo.OutputMimeType
+		}
+		return extInfo.OutputMimeType + "; charset=" + extInfo.Charset
+	}
+	if len(extInfo.MimeType) == 0 {
+		if contentType := mime.TypeByExtension(ext); len(contentType) > 0 {
+			return contentType
+		}
+		return "application/octet-stream"
+	}
+	if len(extInfo.Charset) == 0 {
+		return extInfo.MimeType
+	}
+	return extInfo.MimeType + "; charset=" + extInfo.Charset
+}
@@ -0,0 +1,59 @@
+package filecompiler
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/znly/go-sprockets/jsvm"
+)
+
+// DefaultBabelPresets are the presets used by NewBabelCompiler when none is given
+var DefaultBabelPresets = []string{"env", "react"}
+
+// BabelCompiler is here to compile ES2015+ and JSX files into ES5 with Babel standalone running in duktape
+// NewDefaultBabelCompiler runs the embedded babel-standalone, NewBabelCompiler the one given
+type BabelCompiler struct {
+	pool *jsvm.Pool
+}
+
+// NewBabelCompiler returns a new BabelCompiler running the babelStandalone source with the given presets
+func NewBabelCompiler(babelStandalone []byte, presets ...string) (*BabelCompiler, error) {
+	if len(presets) == 0 {
+		presets = DefaultBabelPresets
+	}
+	options, err := json.Marshal(map[string][]string{"presets": presets})
+	if err != nil {
+		return nil, err
+	}
+	pool, err := jsvm.NewPool(1, string(babelStandalone), `var babelOptions = `+string(options)+`;
+function babelCompile(content, path) {
+	try {
+		babelOptions.filename = path;
+		return JSON.stringify({code: Babel.transform(content, babelOptions).code});
+	} catch(e) {
+		return JSON.stringify({error: e.toString()});
+	}
+}`)
+	if err != nil {
+		return nil, errors.New("could not load babel: " + err.Error())
+	}
+	return &BabelCompiler{pool}, nil
+}
+
+// NewDefaultBabelCompiler returns a new BabelCompiler running the embedded babel-standalone with the default presets
+func NewDefaultBabelCompiler() (*BabelCompiler, error) {
+	babelStandalone, err := jsvm.Gunzip(libbabel)
+	if err != nil {
+		return nil, err
+	}
+	return NewBabelCompiler([]byte(babelStandalone))
+}
+
+// Process to implement ContentTreatmentInterface
+func (bc *BabelCompiler) Process(content []byte, path string) ([]byte, error) {
+	code, err := bc.pool.Call("babelCompile", nil, string(content), path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(code), nil
+}
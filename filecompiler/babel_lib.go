@@ -0,0 +1,5 @@
+package filecompiler
+
+// libbabel is the gzipped babel.min.js of babel-standalone, written by go generate in the jsvm package
+// (it is not in the repository as it weighs several MB, NewDefaultBabelCompiler returns jsvm.ErrNotEmbedded until then)
+var libbabel []byte
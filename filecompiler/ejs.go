@@ -0,0 +1,60 @@
+package filecompiler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EJSCompiler is here to compile an EJS template into a javascript file registering its function in window.JST
+// under its path relative to Root without its extensions (ie: JST["templates/user"] for [Root]/templates/user.jst.ejs)
+// As with the Rails Sprockets, <%= %> outputs a value, <%- %> outputs an escaped value, <%# %> is a comment
+// and <% %> runs some code, the template function is given an object holding the template variables.
+type EJSCompiler struct {
+	Root string
+}
+
+// Process to implement ContentTreatmentInterface
+func (ec *EJSCompiler) Process(content []byte, path string) ([]byte, error) {
+	function, err := compileEJS(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return jstWrap(jstName(ec.Root, path), "", function), nil
+}
+
+func compileEJS(template string) (string, error) {
+	var buf strings.Builder
+	buf.WriteString("function(obj) {\nvar __t, __p = [], __e = " + jsEscape + ", print = function() { __p.push.apply(__p, arguments); };\nwith (obj || {}) {\n")
+	line := 1
+	for len(template) > 0 {
+		start := strings.Index(template, "<%")
+		if start < 0 {
+			start = len(template)
+		}
+		if start > 0 {
+			buf.WriteString("__p.push(" + jsString(template[:start]) + ");\n")
+			line += strings.Count(template[:start], "\n")
+		}
+		if start == len(template) {
+			break
+		}
+		end := strings.Index(template[start:], "%>")
+		if end < 0 {
+			return "", fmt.Errorf("line %d: unterminated <%% tag", line)
+		}
+		tag := template[start+2 : start+end]
+		template = template[start+end+2:]
+		line += strings.Count(tag, "\n")
+		switch {
+		case strings.HasPrefix(tag, "="):
+			buf.WriteString("__p.push((__t = (" + tag[1:] + ")) == null ? '' : __t);\n")
+		case strings.HasPrefix(tag, "-"):
+			buf.WriteString("__p.push(__e(" + tag[1:] + "));\n")
+		case strings.HasPrefix(tag, "#"):
+		default:
+			buf.WriteString(tag + "\n")
+		}
+	}
+	buf.WriteString("}\nreturn __p.join('');\n}")
+	return buf.String(), nil
+}
@@ -0,0 +1,38 @@
+package filecompiler
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// jstName returns the key of a template in window.JST: its path relative to root without its extensions
+// (ie: "templates/user" for [root]/templates/user.jst.ejs)
+func jstName(root, path string) string {
+	name, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(name, "..") {
+		name = filepath.Base(path)
+	}
+	dir, base := filepath.Split(name)
+	return filepath.ToSlash(filepath.Join(dir, strings.SplitN(base, ".", 2)[0]))
+}
+
+// jsString returns s as a javascript string literal
+func jsString(s string) string {
+	ret, _ := json.Marshal(s)
+	return string(ret)
+}
+
+// jstWrap returns the javascript registering the template function in window.JST under name
+// runtime is some code the function needs, it is run once before
+func jstWrap(name, runtime, function string) []byte {
+	return []byte("(function() {\n" +
+		"var root = this;\n" +
+		"root.JST || (root.JST = {});\n" +
+		runtime +
+		"root.JST[" + jsString(name) + "] = " + function + ";\n" +
+		"}).call(this);\n")
+}
+
+// jsEscape is the javascript function escaping the html special characters of a value
+const jsEscape = `function(s) { return String(s == null ? "" : s).replace(/[&<>"']/g, function(c) { return {"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;"}[c]; }); }`
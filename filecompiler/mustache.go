@@ -0,0 +1,217 @@
+package filecompiler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MustacheCompiler is here to compile a Mustache or Handlebars template into a javascript file registering its function
+// in window.JST under its path relative to Root without its extensions (ie: JST["templates/user"] for [Root]/templates/user.hbs)
+// It supports the variables ({{name}}, {{{name}}}, {{&name}}), sections ({{#name}}, {{^name}}, {{else}}), comments,
+// partials ({{> name}} renders JST["name"]) and the if, unless, each (with @index, @key, @first, @last) and with helpers.
+type MustacheCompiler struct {
+	Root string
+}
+
+// mustacheRuntime holds the functions used by the compiled templates, shared by all of them in JST.__mustache
+const mustacheRuntime = `var m = root.JST.__mustache || (root.JST.__mustache = {
+	escape: ` + jsEscape + `,
+	str: function(v) { return v == null ? "" : String(v); },
+	empty: function() { return ""; },
+	truthy: function(v) { return !!v && !(v instanceof Array && v.length === 0); },
+	lookup: function(s, d, name) {
+		var top = s[s.length - 1], names = name.split("."), value, i;
+		if (name === "." || name === "this") { return top; }
+		if (name.charAt(0) === "@") { return d[d.length - 1][name.slice(1)]; }
+		if (names[0] === "this") {
+			value = top;
+		} else {
+			for (i = s.length - 1; i >= 0; i--) {
+				if (s[i] != null && typeof s[i] === "object" && names[0] in s[i]) { value = s[i][names[0]]; break; }
+			}
+		}
+		for (i = 1; i < names.length && value != null; i++) { value = value[names[i]]; }
+		return typeof value === "function" ? value.call(top) : value;
+	},
+	section: function(s, d, value, fn, inverse) {
+		if (!this.truthy(value)) { return inverse(s, d); }
+		if (value instanceof Array) { return this.each(s, d, value, fn, inverse); }
+		if (typeof value === "object") { return this.within(s, d, value, fn, inverse); }
+		return fn(s, d);
+	},
+	each: function(s, d, value, fn, inverse) {
+		var o = "", keys = [], i;
+		if (value == null || typeof value !== "object") { return inverse(s, d); }
+		if (value instanceof Array) {
+			for (i = 0; i < value.length; i++) { keys.push(i); }
+		} else {
+			for (i in value) { if (Object.prototype.hasOwnProperty.call(value, i)) { keys.push(i); } }
+		}
+		if (keys.length === 0) { return inverse(s, d); }
+		for (i = 0; i < keys.length; i++) {
+			s.push(value[keys[i]]);
+			d.push({index: i, key: keys[i], first: i === 0, last: i === keys.length - 1});
+			o += fn(s, d);
+			s.pop();
+			d.pop();
+		}
+		return o;
+	},
+	within: function(s, d, value, fn, inverse) {
+		if (value == null) { return inverse(s, d); }
+		s.push(value);
+		var o = fn(s, d);
+		s.pop();
+		return o;
+	},
+	partial: function(name, s) { return root.JST[name] ? root.JST[name](s[s.length - 1]) : ""; }
+});
+`
+
+// Process to implement ContentTreatmentInterface
+func (mc *MustacheCompiler) Process(content []byte, path string) ([]byte, error) {
+	p := &mustacheParser{src: string(content), line: 1}
+	body, end, err := p.block()
+	if err == nil && end != nil {
+		err = fmt.Errorf("line %d: unexpected {{%s}}", end.line, end.source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return jstWrap(jstName(mc.Root, path), mustacheRuntime, "function(view) {\nreturn ("+body+")([view], [{}]);\n}"), nil
+}
+
+type mustacheTag struct {
+	kind   byte // v (escaped variable), & (variable), #, ^, /, !, >, e (else)
+	name   string
+	arg    string
+	line   int
+	source string
+}
+
+type mustacheParser struct {
+	src  string
+	line int
+}
+
+// next returns the text up to the next tag and that tag, nil at the end of the template
+func (p *mustacheParser) next() (string, *mustacheTag, error) {
+	start := strings.Index(p.src, "{{")
+	if start < 0 {
+		text := p.src
+		p.src = ""
+		return text, nil, nil
+	}
+	text := p.src[:start]
+	p.line += strings.Count(text, "\n")
+	closing := "}}"
+	if strings.HasPrefix(p.src[start:], "{{{") {
+		closing = "}}}"
+	}
+	end := strings.Index(p.src[start+len(closing):], closing)
+	if end < 0 {
+		return "", nil, fmt.Errorf("line %d: unterminated tag", p.line)
+	}
+	tag := &mustacheTag{line: p.line, source: strings.TrimSpace(p.src[start+len(closing) : start+len(closing)+end])}
+	p.src = p.src[start+len(closing)+end+len(closing):]
+	p.line += strings.Count(tag.source, "\n")
+	content := tag.source
+	switch {
+	case closing == "}}}":
+		tag.kind = '&'
+	case content == "else":
+		tag.kind = 'e'
+		return text, tag, nil
+	case len(content) > 0 && strings.IndexByte("&#^/!>", content[0]) >= 0:
+		tag.kind = content[0]
+		content = content[1:]
+	case strings.HasPrefix(content, "="):
+		return "", nil, fmt.Errorf("line %d: delimiter changes are not supported", tag.line)
+	default:
+		tag.kind = 'v'
+	}
+	fields := strings.Fields(content)
+	if len(fields) == 0 && tag.kind != '!' {
+		return "", nil, fmt.Errorf("line %d: empty tag", tag.line)
+	}
+	if len(fields) > 0 {
+		tag.name = fields[0]
+		tag.arg = strings.Join(fields[1:], " ")
+	}
+	return text, tag, nil
+}
+
+// lookup returns the javascript looking up name in the context
+func lookup(name string) string {
+	return "m.lookup(s, d, " + jsString(name) + ")"
+}
+
+// block compiles the template up to the end of the current section ({{/name}} or {{else}})
+// Return a javascript function(s, d) (s being the context stack and d the data stack of each) and the tag ending the section
+func (p *mustacheParser) block() (string, *mustacheTag, error) {
+	var buf strings.Builder
+	buf.WriteString("function(s, d) {\nvar o = \"\";\n")
+	for {
+		text, tag, err := p.next()
+		if err != nil {
+			return "", nil, err
+		}
+		if len(text) > 0 {
+			buf.WriteString("o += " + jsString(text) + ";\n")
+		}
+		if tag == nil {
+			break
+		}
+		switch tag.kind {
+		case 'v':
+			buf.WriteString("o += m.escape(" + lookup(tag.name) + ");\n")
+		case '&':
+			buf.WriteString("o += m.str(" + lookup(tag.name) + ");\n")
+		case '>':
+			buf.WriteString("o += m.partial(" + jsString(tag.name) + ", s);\n")
+		case '#', '^':
+			section, err := p.section(tag)
+			if err != nil {
+				return "", nil, err
+			}
+			buf.WriteString("o += " + section + ";\n")
+		case '/', 'e':
+			buf.WriteString("return o;\n}")
+			return buf.String(), tag, nil
+		}
+	}
+	buf.WriteString("return o;\n}")
+	return buf.String(), nil, nil
+}
+
+// section compiles a section opened by tag, returns the javascript expression of its output
+func (p *mustacheParser) section(tag *mustacheTag) (string, error) {
+	fn, end, err := p.block()
+	if err != nil {
+		return "", err
+	}
+	inverse := "m.empty"
+	if end != nil && end.kind == 'e' {
+		if inverse, end, err = p.block(); err != nil {
+			return "", err
+		}
+	}
+	if end == nil || end.kind != '/' || end.name != tag.name {
+		return "", fmt.Errorf("line %d: unclosed section {{%s}}", tag.line, tag.source)
+	}
+	if tag.kind == '^' {
+		fn, inverse = inverse, fn
+		return "(m.truthy(" + lookup(tag.name) + ") ? " + fn + " : " + inverse + ")(s, d)", nil
+	}
+	switch {
+	case tag.name == "if" && tag.arg != "":
+		return "(m.truthy(" + lookup(tag.arg) + ") ? " + fn + " : " + inverse + ")(s, d)", nil
+	case tag.name == "unless" && tag.arg != "":
+		return "(m.truthy(" + lookup(tag.arg) + ") ? " + inverse + " : " + fn + ")(s, d)", nil
+	case tag.name == "each" && tag.arg != "":
+		return "m.each(s, d, " + lookup(tag.arg) + ", " + fn + ", " + inverse + ")", nil
+	case tag.name == "with" && tag.arg != "":
+		return "m.within(s, d, " + lookup(tag.arg) + ", " + fn + ", " + inverse + ")", nil
+	}
+	return "m.section(s, d, " + lookup(tag.name) + ", " + fn + ", " + inverse + ")", nil
+}
@@ -0,0 +1,73 @@
+package filecompiler
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/znly/go-sprockets/jsvm"
+)
+
+// DefaultTypeScriptOptions are the compiler options used by NewTypeScriptCompiler when none is given
+var DefaultTypeScriptOptions = map[string]interface{}{"target": "ES5"}
+
+// TypeScriptCompiler is here to compile a typescript file into a js file with the transpileModule function
+// of the TypeScript compiler (no type checking) running in a pool of duktape contexts
+// NewDefaultTypeScriptCompiler runs the embedded TypeScript, NewTypeScriptCompiler the one given
+type TypeScriptCompiler struct {
+	pool *jsvm.Pool
+}
+
+// NewTypeScriptCompiler returns a new TypeScriptCompiler running the typescript source with the given compiler options
+// in at most poolSize contexts
+func NewTypeScriptCompiler(typescript []byte, compilerOptions map[string]interface{}, poolSize int) (*TypeScriptCompiler, error) {
+	if compilerOptions == nil {
+		compilerOptions = DefaultTypeScriptOptions
+	}
+	options, err := json.Marshal(compilerOptions)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := jsvm.NewPool(poolSize, string(typescript), `var typescriptOptions = `+string(options)+`;
+function typescriptCompile(content, path) {
+	try {
+		var out = ts.transpileModule(content, {compilerOptions: typescriptOptions, fileName: path, reportDiagnostics: true});
+		var errors = (out.diagnostics || []).filter(function(d) { return d.category === ts.DiagnosticCategory.Error; });
+		if (errors.length > 0) {
+			return JSON.stringify({error: errors.map(function(d) {
+				var message = ts.flattenDiagnosticMessageText(d.messageText, "\n");
+				if (!d.file) {
+					return message;
+				}
+				var pos = d.file.getLineAndCharacterOfPosition(d.start);
+				return path + ":" + (pos.line + 1) + ":" + (pos.character + 1) + ": " + message;
+			}).join("\n")});
+		}
+		return JSON.stringify({code: out.outputText});
+	} catch(e) {
+		return JSON.stringify({error: e.toString()});
+	}
+}`)
+	if err != nil {
+		return nil, errors.New("could not load typescript: " + err.Error())
+	}
+	return &TypeScriptCompiler{pool}, nil
+}
+
+// NewDefaultTypeScriptCompiler returns a new TypeScriptCompiler running the embedded TypeScript
+// with the default compiler options in at most poolSize contexts
+func NewDefaultTypeScriptCompiler(poolSize int) (*TypeScriptCompiler, error) {
+	typescript, err := jsvm.Gunzip(libtypescript)
+	if err != nil {
+		return nil, err
+	}
+	return NewTypeScriptCompiler([]byte(typescript), nil, poolSize)
+}
+
+// Process to implement ContentTreatmentInterface
+func (tc *TypeScriptCompiler) Process(content []byte, path string) ([]byte, error) {
+	code, err := tc.pool.Call("typescriptCompile", nil, string(content), path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(code), nil
+}
@@ -0,0 +1,5 @@
+package filecompiler
+
+// libtypescript is the gzipped lib/typescript.js of typescript, written by go generate in the jsvm package
+// (it is not in the repository as it weighs several MB, NewDefaultTypeScriptCompiler returns jsvm.ErrNotEmbedded until then)
+var libtypescript []byte
@@ -0,0 +1,95 @@
+package sprockets
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/znly/go-sprockets/types"
+)
+
+// ServeHTTP serves the assets, the url path being the asset path
+// With the body=1 query, the asset is served compiled without its requirements (see GetDebugAssets)
+// Use http.StripPrefix to serve the assets under a prefix
+// Absolute paths, paths with a ".." segment and files outside of the assets directories are not found
+func (s *Sprocket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	assetPath, ok := s.servedAssetPath(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var content []byte
+	var err error
+	if r.URL.Query().Get("body") == "1" {
+		content, err = s.GetAssetBody(assetPath)
+	} else {
+		content, err = s.GetAsset(assetPath)
+	}
+	if err == ErrNotFound {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", s.GetContentType(assetPath))
+	http.ServeContent(w, r, assetPath, time.Time{}, bytes.NewReader(content))
+}
+
+// servedAssetPath returns the cleaned asset path of urlPath and false if it must not be served
+func (s *Sprocket) servedAssetPath(urlPath string) (string, bool) {
+	assetPath := strings.TrimPrefix(urlPath, "/")
+	if len(assetPath) == 0 || path.IsAbs(assetPath) {
+		return "", false
+	}
+	for _, segment := range strings.Split(assetPath, "/") {
+		if segment == ".." {
+			return "", false
+		}
+	}
+	assetPath = path.Clean(assetPath)
+	realPath, extInfo, err := s.resolvePath(assetPath, "", true)
+	if err != nil {
+		// not found in the assets, GetAsset looks in the public directory or returns ErrNotFound
+		return assetPath, true
+	}
+	return assetPath, s.inAssetsDirs(realPath, extInfo)
+}
+
+// inAssetsDirs returns true if realPath is in the assets directory, one of the paths of extInfo
+// or found by one of its path resolvers
+func (s *Sprocket) inAssetsDirs(realPath string, extInfo *types.ExtensionInfo) bool {
+	if isInDir(realPath, s.assetsPath) {
+		return true
+	}
+	for e := extInfo.Paths.Front(); e != nil; e = e.Next() {
+		if isInDir(realPath, e.Value) {
+			return true
+		}
+	}
+	for _, resolver := range extInfo.PathResolvers {
+		if _, ok := resolver.LogicalPath(realPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isInDir returns true if path is dir or one of its descendants, symlinks being evaluated as the resolved paths are
+func isInDir(path, dir string) bool {
+	if evalDir, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = evalDir
+	}
+	if absDir, err := filepath.Abs(dir); err == nil {
+		dir = absDir
+	}
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
@@ -0,0 +1,71 @@
+package sprockets
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/znly/go-sprockets/types"
+)
+
+// importCandidates returns the file names an import can refer to, the partial (_name) first
+func importCandidates(extInfo *types.ExtensionInfo, importPath string) []string {
+	dir, base := filepath.Split(importPath)
+	names := []string{filepath.Join(dir, "_"+base), importPath}
+	if filepath.Ext(base) != "" {
+		return names
+	}
+	candidates := make([]string, 0, len(names)*(extInfo.AlterExts.Len()+1))
+	for _, name := range names {
+		candidates = append(candidates, name+extInfo.CurrentExtension)
+		for e := extInfo.AlterExts.Front(); e != nil; e = e.Next() {
+			if e.Value != extInfo.CurrentExtension {
+				candidates = append(candidates, name+e.Value)
+			}
+		}
+	}
+	return candidates
+}
+
+// resolveImport resolves a file imported by parentPath
+// it is searched relatively to parentPath then through the paths of the extension
+func (s *Sprocket) resolveImport(extInfo *types.ExtensionInfo, importPath, parentPath string) (string, error) {
+	dirs := []string{filepath.Dir(parentPath)}
+	if !filepath.IsAbs(importPath) {
+		for e := extInfo.Paths.Front(); e != nil; e = e.Next() {
+			dirs = append(dirs, e.Value)
+		}
+	}
+	for _, dir := range dirs {
+		for _, candidate := range importCandidates(extInfo, importPath) {
+			if !filepath.IsAbs(candidate) {
+				candidate = filepath.Join(dir, candidate)
+			}
+			if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+				if newPath, err := filepath.EvalSymlinks(candidate); err == nil {
+					candidate = newPath
+				}
+				return candidate, nil
+			}
+		}
+	}
+	return "", ErrNotFound
+}
+
+// bundle runs the BundleCompiler of extInfo on the full content of an asset
+// and returns the files it imported
+func (s *Sprocket) bundle(assetPath string, extInfo *types.ExtensionInfo, fullContent []byte) ([]byte, []string, error) {
+	importer, ok := extInfo.BundleCompiler.(types.ImporterInterface)
+	if !ok {
+		fullContent, err := extInfo.BundleCompiler.Process(fullContent, assetPath)
+		return fullContent, nil, err
+	}
+	var imports []string
+	fullContent, err := importer.ProcessWithImports(fullContent, assetPath, func(importPath, parentPath string) (string, error) {
+		importedPath, err := s.resolveImport(extInfo, importPath, parentPath)
+		if err == nil {
+			imports = append(imports, importedPath)
+		}
+		return importedPath, err
+	})
+	return fullContent, imports, err
+}
@@ -0,0 +1,18 @@
+package sprockets
+
+import "github.com/znly/go-sprockets/compressor"
+
+// SetJSDefines replaces the compile-time constants of defines (ie: "__DEV__", "process.env.NODE_ENV") by their value
+// in each javascript file, then strips the dead branches of the if statements (see compressor.JSDefiner)
+// If dropConsole is true, the console.* calls and debugger statements are removed in production mode
+// (see SetProduction)
+func (s *Sprocket) SetJSDefines(defines map[string]interface{}, dropConsole bool) {
+	if s.jsDefiner == nil {
+		s.jsDefiner = &compressor.JSDefiner{}
+		s.RegisterPostprocessor("application/javascript", s.jsDefiner)
+	}
+	s.jsDefiner.Defines = defines
+	s.jsDropConsole = dropConsole
+	s.jsDefiner.DropConsole = dropConsole && s.production
+	s.processors.generation++
+}
@@ -0,0 +1,30 @@
+package jsvm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+)
+
+//go:generate go run embedsource.go
+
+// ErrNotEmbedded is returned when a compiler source has not been embedded yet (run go generate in jsvm)
+var ErrNotEmbedded = errors.New("compiler source not embedded, run go generate in the jsvm package")
+
+// Gunzip returns the source embedded gzipped in lib, or ErrNotEmbedded if lib is empty
+func Gunzip(lib []byte) (string, error) {
+	if len(lib) == 0 {
+		return "", ErrNotEmbedded
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(lib))
+	if err != nil {
+		return "", err
+	}
+	defer gz.Close()
+	source, err := ioutil.ReadAll(gz)
+	if err != nil {
+		return "", err
+	}
+	return string(source), nil
+}
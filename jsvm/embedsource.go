@@ -0,0 +1,114 @@
+//go:build ignore
+// +build ignore
+
+// embedsource downloads the javascript compilers from npm and writes them gzipped in go files,
+// like the libcoffee of filecompiler/coffee.go
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+)
+
+type source struct {
+	tarball string // url of the npm package
+	file    string // path of the source in the package
+	output  string // go file to write, from the jsvm directory
+	pkg     string
+	name    string // name of the []byte var
+}
+
+var sources = []source{
+	{
+		tarball: "https://registry.npmjs.org/babel-standalone/-/babel-standalone-6.26.0.tgz",
+		file:    "package/babel.min.js",
+		output:  "../filecompiler/babel_lib.go",
+		pkg:     "filecompiler",
+		name:    "libbabel",
+	},
+	{
+		tarball: "https://registry.npmjs.org/typescript/-/typescript-2.7.2.tgz",
+		file:    "package/lib/typescript.js",
+		output:  "../filecompiler/typescript_lib.go",
+		pkg:     "filecompiler",
+		name:    "libtypescript",
+	},
+	{
+		tarball: "https://registry.npmjs.org/less/-/less-3.0.1.tgz",
+		file:    "package/dist/less.js",
+		output:  "../bundlecompiler/less_lib.go",
+		pkg:     "bundlecompiler",
+		name:    "libless",
+	},
+}
+
+func main() {
+	for _, src := range sources {
+		content, err := download(src.tarball, src.file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := write(src, content); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// download returns the file of the npm tarball
+func download(tarball, file string) ([]byte, error) {
+	resp, err := http.Get(tarball)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download %s: %s", tarball, resp.Status)
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s is not in %s", file, tarball)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Name == file {
+			return ioutil.ReadAll(archive)
+		}
+	}
+}
+
+// write gzips content into the var of the go file
+func write(src source, content []byte) error {
+	var gzipped bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&gzipped, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := gz.Write(content); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by jsvm/embedsource.go; DO NOT EDIT.\n\npackage %s\n\n", src.pkg)
+	fmt.Fprintf(&out, "//Copy of %s of %s\nvar %s = []byte(\"", src.file, src.tarball, src.name)
+	for _, b := range gzipped.Bytes() {
+		fmt.Fprintf(&out, "\\x%02x", b)
+	}
+	out.WriteString("\")\n")
+	return ioutil.WriteFile(src.output, out.Bytes(), 0644)
+}
@@ -0,0 +1,111 @@
+// Package jsvm runs the javascript compilers (babel, typescript, less...) in pools of duktape contexts
+package jsvm
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	duktape "gopkg.in/olebedev/go-duktape.v3"
+)
+
+// Pool is a pool of duktape contexts with the same sources loaded, created when needed
+type Pool struct {
+	sources []string
+	vms     chan *duktape.Context
+	mutex   sync.Mutex
+	created int
+}
+
+// Result is the JSON the functions run with Pool.Call must return
+type Result struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+// NewPool returns a pool of at most size contexts running sources
+// one context is created to check the sources
+func NewPool(size int, sources ...string) (*Pool, error) {
+	if size < 1 {
+		size = 1
+	}
+	p := &Pool{sources: sources, vms: make(chan *duktape.Context, size)}
+	vm, err := p.newVM()
+	if err != nil {
+		return nil, err
+	}
+	p.created = 1
+	p.vms <- vm
+	return p, nil
+}
+
+func (p *Pool) newVM() (*duktape.Context, error) {
+	vm := duktape.New()
+	for _, source := range p.sources {
+		if err := vm.PevalString(source); err != nil {
+			vm.DestroyHeap()
+			return nil, err
+		}
+		vm.Pop()
+	}
+	return vm, nil
+}
+
+func (p *Pool) get() (*duktape.Context, error) {
+	select {
+	case vm := <-p.vms:
+		return vm, nil
+	default:
+	}
+	p.mutex.Lock()
+	if p.created < cap(p.vms) {
+		p.created++
+		p.mutex.Unlock()
+		vm, err := p.newVM()
+		if err != nil {
+			p.mutex.Lock()
+			p.created--
+			p.mutex.Unlock()
+		}
+		return vm, err
+	}
+	p.mutex.Unlock()
+	return <-p.vms, nil
+}
+
+// Call runs the global function fn with args and returns the code of its Result
+// goFunctions are set as global functions of the context for this call and removed afterwards
+func (p *Pool) Call(fn string, goFunctions map[string]func(*duktape.Context) int, args ...string) (string, error) {
+	vm, err := p.get()
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		// remove the go functions so they do not leak to the next caller of this context
+		for name := range goFunctions {
+			vm.PushGlobalObject()
+			vm.DelPropString(-1, name)
+			vm.Pop()
+		}
+		p.vms <- vm
+	}()
+	for name, goFunction := range goFunctions {
+		vm.PushGoFunction(goFunction)
+		vm.PutGlobalString(name)
+	}
+	vm.EvalString(fn)
+	for _, arg := range args {
+		vm.PushString(arg)
+	}
+	vm.Call(len(args))
+	var result Result
+	err = json.Unmarshal([]byte(vm.GetString(-1)), &result)
+	vm.Pop()
+	if err != nil {
+		return "", err
+	}
+	if len(result.Error) > 0 {
+		return "", errors.New(result.Error)
+	}
+	return result.Code, nil
+}
@@ -0,0 +1,112 @@
+package sprockets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/znly/go-sprockets/types"
+)
+
+// ManifestFileName is the name of the manifest written in the public path
+const ManifestFileName = "manifest.json"
+
+// ManifestFile describes a digest file written in the public path
+type ManifestFile struct {
+	LogicalPath string           `json:"logical_path"`
+	ContentType string           `json:"content_type"`
+	MTime       time.Time        `json:"mtime"`
+	Size        int              `json:"size"`
+	Digest      string           `json:"digest"`
+	Integrity   *types.Integrity `json:"integrity"`
+}
+
+// Manifest maps the logical path of the generated assets to their digest path
+type Manifest struct {
+	Files  map[string]*ManifestFile `json:"files"`
+	Assets map[string]string        `json:"assets"`
+	mutex  sync.RWMutex
+}
+
+func newManifest() *Manifest {
+	return &Manifest{
+		Files:  make(map[string]*ManifestFile),
+		Assets: make(map[string]string),
+	}
+}
+
+// readManifest loads the manifest of the public path, a missing manifest is not an error
+func (s *Sprocket) readManifest() error {
+	content, err := ioutil.ReadFile(filepath.Join(s.publicPath, ManifestFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, s.manifest)
+}
+
+// writeToManifest writes the digest file of an asset in the public path and records it in the manifest
+func (s *Sprocket) writeToManifest(assetPath string, FullContent []byte, integrity *types.Integrity) error {
+	digest := hexDigest(FullContent)
+	logicalPath := filepath.ToSlash(strings.TrimPrefix(assetPath, "/"))
+	ext := filepath.Ext(logicalPath)
+	digestPath := strings.TrimSuffix(logicalPath, ext) + "-" + digest + ext
+	if err := ioutil.WriteFile(filepath.Join(s.publicPath, digestPath), FullContent, os.FileMode(0640)); err != nil {
+		return err
+	}
+	s.manifest.mutex.Lock()
+	defer s.manifest.mutex.Unlock()
+	s.manifest.Assets[logicalPath] = digestPath
+	s.manifest.Files[digestPath] = &ManifestFile{
+		LogicalPath: logicalPath,
+		ContentType: s.GetContentType(logicalPath),
+		MTime:       time.Now(),
+		Size:        len(FullContent),
+		Digest:      digest,
+		Integrity:   integrity,
+	}
+	content, err := json.MarshalIndent(s.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.publicPath, ManifestFileName), content, os.FileMode(0640))
+}
+
+// GetDigestPath will return the digest path of an asset found in the manifest
+// ok is false if the asset has not been generated yet
+func (s *Sprocket) GetDigestPath(assetPath string) (digestPath string, ok bool) {
+	s.manifest.mutex.RLock()
+	defer s.manifest.mutex.RUnlock()
+	digestPath, ok = s.manifest.Assets[filepath.ToSlash(strings.TrimPrefix(assetPath, "/"))]
+	return
+}
+
+// getDigestIntegrity will return the Subresource Integrity digests of a digest file of the manifest
+// the file is read if the manifest does not hold them
+func (s *Sprocket) getDigestIntegrity(digestPath string) (*types.Integrity, error) {
+	s.manifest.mutex.RLock()
+	file, ok := s.manifest.Files[digestPath]
+	s.manifest.mutex.RUnlock()
+	if ok && file.Integrity != nil {
+		return file.Integrity, nil
+	}
+	content, err := ioutil.ReadFile(filepath.Join(s.publicPath, filepath.FromSlash(digestPath)))
+	if err != nil {
+		return nil, err
+	}
+	return types.NewIntegrity(content), nil
+}
+
+// hexDigest returns the hexadecimal SHA-256 of content
+func hexDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package sprockets
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/znly/go-sprockets/types"
+)
+
+// commonJSRuntime defines the define and require functions of the CommonJS wrapping in the __sprockets global, once
+// (the define and require globals of other loaders are left untouched)
+// The relative names are resolved from the name of the requiring module
+const commonJSRuntime = `(function(root) {
+if (root.__sprockets) { return; }
+var factories = {}, modules = {};
+function resolve(name, parent) {
+	name = name.replace(/\.js$/, "");
+	if (name.charAt(0) !== ".") { return name; }
+	var parts = parent.split("/").slice(0, -1), names = name.split("/");
+	for (var i = 0; i < names.length; i++) {
+		if (names[i] === "..") { parts.pop(); } else if (names[i] !== ".") { parts.push(names[i]); }
+	}
+	return parts.join("/");
+}
+function load(name) {
+	if (!modules.hasOwnProperty(name)) {
+		if (!factories.hasOwnProperty(name)) {
+			if (factories.hasOwnProperty(name + "/index")) { return load(name + "/index"); }
+			throw new Error("Cannot find module '" + name + "'");
+		}
+		var module = modules[name] = {id: name, exports: {}};
+		factories[name].call(module.exports, function(dep) { return load(resolve(dep, name)); }, module, module.exports);
+	}
+	return modules[name].exports;
+}
+root.__sprockets = {
+	define: function(name, factory) { factories[name] = factory; delete modules[name]; },
+	require: function(name) { return load(resolve(name, "")); }
+};
+})(this);
+`
+
+// SetModuleWrapping set the way the content of each file of this extension is wrapped when bundled
+func (s *Sprocket) SetModuleWrapping(ext string, wrapping types.ModuleWrapping) {
+	extInfo := s.getOrCreateExtensionInfo(ext)
+	extInfo.ModuleWrapping = wrapping
+}
+
+// moduleName returns the name of the module of a file: its logical path without extension (ie: "widgets/foo")
+func (s *Sprocket) moduleName(path string) string {
+	logicalPath := s.logicalPath(path, "")
+	return strings.TrimSuffix(logicalPath, filepath.Ext(logicalPath))
+}
+
+// globalName returns the name of the global variable of a module (ie: "datePicker" for "widgets/date-picker")
+func globalName(moduleName string) string {
+	var ret []byte
+	upper := false
+	for _, c := range []byte(filepath.Base(moduleName)) {
+		switch {
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '$' || c >= '0' && c <= '9' && len(ret) > 0:
+			if upper && c >= 'a' && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+			ret = append(ret, c)
+			upper = false
+		default:
+			upper = len(ret) > 0
+		}
+	}
+	if len(ret) == 0 {
+		return "module"
+	}
+	return string(ret)
+}
+
+// wrapModule wraps the content of a file according to the ModuleWrapping of its extension
+// Return the content and if it needs the CommonJS runtime, the CommonJS modules are only defined (see commonJSRequire)
+func (s *Sprocket) wrapModule(path string, content []byte) ([]byte, bool) {
+	name, _ := json.Marshal(s.moduleName(path))
+	switch s.getExtensionInfoOrDefault(assetExt(path)).ModuleWrapping {
+	case types.IIFEWrapping:
+		return []byte("(function() {\n" + string(content) + "\n}).call(this);\n"), false
+	case types.CommonJSWrapping:
+		return []byte("__sprockets.define(" + string(name) + ", function(require, module, exports) {\n" + string(content) + "\n});\n"), true
+	case types.UMDWrapping:
+		global, _ := json.Marshal(globalName(s.moduleName(path)))
+		return []byte("(function(root, factory) {\n" +
+			"if (typeof define === \"function\" && define.amd) { define(" + string(name) + ", [], factory); }\n" +
+			"else if (typeof module === \"object\" && module.exports) { module.exports = factory(); }\n" +
+			"else { root[" + string(global) + "] = factory(); }\n" +
+			"}(this, function() {\nvar module = {exports: {}}, exports = module.exports;\n" + string(content) + "\nreturn module.exports;\n}));\n"), false
+	}
+	return content, false
+}
+
+// commonJSRequire returns the calls running the CommonJS modules of a bundle once they are all defined:
+// the entry file if it is one of them (it requires the others), else each of them in the bundle order
+func (s *Sprocket) commonJSRequire(entry string, modules []string) []byte {
+	for _, path := range modules {
+		if path == entry {
+			modules = []string{entry}
+			break
+		}
+	}
+	var ret []byte
+	for _, path := range modules {
+		name, _ := json.Marshal(s.moduleName(path))
+		ret = append(ret, "__sprockets.require("+string(name)+");\n"...)
+	}
+	return ret
+}
@@ -0,0 +1,96 @@
+package postcompiler
+
+// anyVersion is used for the browsers still needing a prefix in their last version
+const anyVersion = 1e9
+
+// browserVersions gives for each browser the version below which a prefix is needed
+type browserVersions map[string]float64
+
+var (
+	webkitFlexbox    = browserVersions{"safari": 9, "ios": 9, "chrome": 29, "android": 4.4, "opera": 16}
+	msFlexbox        = browserVersions{"ie": 11}
+	webkitTransform  = browserVersions{"safari": 9, "ios": 9, "chrome": 36, "android": 5, "opera": 23}
+	msTransform      = browserVersions{"ie": 10}
+	webkitTransition = browserVersions{"safari": 7, "ios": 7, "chrome": 26, "android": 4.4, "opera": 15}
+	webkitAnimation  = browserVersions{"safari": 9, "ios": 9, "chrome": 43, "android": 5, "opera": 30}
+	webkitUserSelect = browserVersions{"safari": anyVersion, "ios": anyVersion, "chrome": 54, "android": anyVersion, "opera": 41}
+	mozUserSelect    = browserVersions{"firefox": 69}
+	msUserSelect     = browserVersions{"ie": anyVersion, "edge": 79}
+	webkitAppearance = browserVersions{"safari": 15.4, "ios": 15.4, "chrome": 84, "android": 84, "opera": 70, "edge": 84}
+	mozAppearance    = browserVersions{"firefox": 80}
+	webkitBackface   = browserVersions{"safari": 15.4, "ios": 15.4, "chrome": 36, "android": 5, "opera": 23}
+)
+
+// propertyPrefix is a prefixed version of a property
+type propertyPrefix struct {
+	name     string
+	browsers browserVersions
+	// values maps the values of the property to the values of the prefixed property, nil to keep them
+	values map[string]string
+}
+
+// msFlexAlign maps the values of the alignment properties to the ones of the IE 10 flexbox
+var msFlexAlign = map[string]string{
+	"flex-start": "start", "flex-end": "end", "center": "center", "baseline": "baseline", "stretch": "stretch",
+	"space-between": "justify", "space-around": "distribute",
+}
+
+func webkit(property string, browsers browserVersions) propertyPrefix {
+	return propertyPrefix{"-webkit-" + property, browsers, nil}
+}
+
+// propertyPrefixes is the table of the prefixed properties
+var propertyPrefixes = map[string][]propertyPrefix{
+	"flex":            {webkit("flex", webkitFlexbox), {"-ms-flex", msFlexbox, nil}},
+	"flex-direction":  {webkit("flex-direction", webkitFlexbox), {"-ms-flex-direction", msFlexbox, nil}},
+	"flex-wrap":       {webkit("flex-wrap", webkitFlexbox), {"-ms-flex-wrap", msFlexbox, nil}},
+	"flex-flow":       {webkit("flex-flow", webkitFlexbox), {"-ms-flex-flow", msFlexbox, nil}},
+	"flex-grow":       {webkit("flex-grow", webkitFlexbox), {"-ms-flex-positive", msFlexbox, nil}},
+	"flex-shrink":     {webkit("flex-shrink", webkitFlexbox), {"-ms-flex-negative", msFlexbox, nil}},
+	"flex-basis":      {webkit("flex-basis", webkitFlexbox), {"-ms-flex-preferred-size", msFlexbox, nil}},
+	"order":           {webkit("order", webkitFlexbox), {"-ms-flex-order", msFlexbox, nil}},
+	"justify-content": {webkit("justify-content", webkitFlexbox), {"-ms-flex-pack", msFlexbox, msFlexAlign}},
+	"align-items":     {webkit("align-items", webkitFlexbox), {"-ms-flex-align", msFlexbox, msFlexAlign}},
+	"align-self":      {webkit("align-self", webkitFlexbox), {"-ms-flex-item-align", msFlexbox, msFlexAlign}},
+	"align-content":   {webkit("align-content", webkitFlexbox), {"-ms-flex-line-pack", msFlexbox, msFlexAlign}},
+
+	"transform":           {webkit("transform", webkitTransform), {"-ms-transform", msTransform, nil}},
+	"transform-origin":    {webkit("transform-origin", webkitTransform), {"-ms-transform-origin", msTransform, nil}},
+	"transform-style":     {webkit("transform-style", webkitTransform)},
+	"perspective":         {webkit("perspective", webkitTransform)},
+	"perspective-origin":  {webkit("perspective-origin", webkitTransform)},
+	"backface-visibility": {webkit("backface-visibility", webkitBackface)},
+
+	"transition":                 {webkit("transition", webkitTransition)},
+	"transition-property":        {webkit("transition-property", webkitTransition)},
+	"transition-duration":        {webkit("transition-duration", webkitTransition)},
+	"transition-timing-function": {webkit("transition-timing-function", webkitTransition)},
+	"transition-delay":           {webkit("transition-delay", webkitTransition)},
+
+	"animation":                 {webkit("animation", webkitAnimation)},
+	"animation-name":            {webkit("animation-name", webkitAnimation)},
+	"animation-duration":        {webkit("animation-duration", webkitAnimation)},
+	"animation-timing-function": {webkit("animation-timing-function", webkitAnimation)},
+	"animation-delay":           {webkit("animation-delay", webkitAnimation)},
+	"animation-iteration-count": {webkit("animation-iteration-count", webkitAnimation)},
+	"animation-direction":       {webkit("animation-direction", webkitAnimation)},
+	"animation-fill-mode":       {webkit("animation-fill-mode", webkitAnimation)},
+	"animation-play-state":      {webkit("animation-play-state", webkitAnimation)},
+
+	"user-select": {webkit("user-select", webkitUserSelect), {"-moz-user-select", mozUserSelect, nil}, {"-ms-user-select", msUserSelect, nil}},
+	"appearance":  {webkit("appearance", webkitAppearance), {"-moz-appearance", mozAppearance, nil}},
+}
+
+// valuePrefix is a prefixed version of a value
+type valuePrefix struct {
+	value    string
+	browsers browserVersions
+}
+
+// valuePrefixes is the table of the prefixed values by property
+var valuePrefixes = map[string]map[string][]valuePrefix{
+	"display": {
+		"flex":        {{"-webkit-flex", webkitFlexbox}, {"-ms-flexbox", msFlexbox}},
+		"inline-flex": {{"-webkit-inline-flex", webkitFlexbox}, {"-ms-inline-flexbox", msFlexbox}},
+	},
+}
@@ -0,0 +1,290 @@
+package postcompiler
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultBrowsers is the browser target list used when VendorPrefixer.Browsers is empty
+var DefaultBrowsers = []string{"ie 10", "edge 12", "firefox 40", "chrome 40", "safari 8", "ios 8", "android 4.4", "opera 30"}
+
+// VendorPrefixer is here to add the vendor prefixes to the css properties (flexbox, transforms, transitions, animations, user-select...)
+// needed by the browser target list, it works on plain css and on the output of the ScssSassCompiler:
+//
+//	s.AddPostCompileContentTreatment(".css", &postcompiler.VendorPrefixer{Browsers: []string{"ie 10", "safari 8"}})
+type VendorPrefixer struct {
+	// Browsers is the list of the oldest supported version of each browser ("name version"),
+	// the names are ie, edge, firefox, chrome, safari, ios, android and opera
+	Browsers []string
+}
+
+var transformValue = regexp.MustCompile(`(^|[\s,])transform\b`)
+
+// Process to implement ContentTreatmentInterface
+func (vp *VendorPrefixer) Process(content []byte, path string) ([]byte, error) {
+	targets, err := parseBrowsers(vp.Browsers)
+	if err != nil {
+		return nil, err
+	}
+	return prefixRules(content, content, path, targets, "")
+}
+
+// prefixRules adds the prefixes needed by the targets to the rules of content, stylesheet being the whole stylesheet
+// If only is not empty, the only prefixes added are the ones starting with it (ie: "-webkit-" in @-webkit-keyframes)
+func prefixRules(content, stylesheet []byte, path string, targets map[string]float64, only string) ([]byte, error) {
+	var out bytes.Buffer
+	last, blockStart := 0, -1
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := bytes.Index(content[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, fmt.Errorf("%s: unterminated comment", path)
+			}
+			i += end + 3
+		case c == '"' || c == '\'':
+			i = skipString(content, i)
+		case c == '@' && hasPrefixFold(content[i:], keyframesRule):
+			open, end := atRuleBlock(content, i)
+			if end < 0 {
+				return nil, fmt.Errorf("%s: unterminated %s", path, keyframesRule)
+			}
+			body, err := prefixRules(content[open+1:end], stylesheet, path, targets, only)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(content[last:i])
+			name := bytes.TrimSpace(content[i+len(keyframesRule) : open])
+			if webkitAnimation.needed(targets) && !bytes.Contains(stylesheet, append([]byte("@-webkit-keyframes "), name...)) {
+				webkitBody, err := prefixRules(content[open+1:end], stylesheet, path, targets, "-webkit-")
+				if err != nil {
+					return nil, err
+				}
+				out.WriteString("@-webkit-keyframes")
+				out.Write(content[i+len(keyframesRule) : open+1])
+				out.Write(webkitBody)
+				out.WriteByte('}')
+				out.WriteString(ruleSeparator(content, i))
+			}
+			out.Write(content[i : open+1])
+			out.Write(body)
+			last, i, blockStart = end, end, -1
+		case c == '{':
+			blockStart = i + 1
+		case c == '}':
+			if blockStart >= 0 {
+				out.Write(content[last:blockStart])
+				out.Write(prefixBlock(content[blockStart:i], targets, only))
+				last = i
+			}
+			blockStart = -1
+		}
+	}
+	out.Write(content[last:])
+	return out.Bytes(), nil
+}
+
+const keyframesRule = "@keyframes"
+
+func hasPrefixFold(src []byte, prefix string) bool {
+	return len(src) >= len(prefix) && strings.EqualFold(string(src[:len(prefix)]), prefix)
+}
+
+// atRuleBlock returns the index of the "{" opening the block of the at-rule starting at src[i]
+// and the index of the "}" closing it, -1 if it is not closed
+func atRuleBlock(src []byte, i int) (int, int) {
+	open, depth := -1, 0
+	for ; i < len(src); i++ {
+		switch src[i] {
+		case '/':
+			if i+1 < len(src) && src[i+1] == '*' {
+				end := bytes.Index(src[i+2:], []byte("*/"))
+				if end < 0 {
+					return open, -1
+				}
+				i += end + 3
+			}
+		case '"', '\'':
+			i = skipString(src, i)
+		case '{':
+			if open < 0 {
+				open = i
+			}
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return open, i
+			}
+		}
+	}
+	return open, -1
+}
+
+// ruleSeparator returns the text to write between a prefixed copy of the rule starting at src[i] and the rule:
+// a newline and the indentation of the rule if it follows a line break, nothing else (ie: compressed stylesheets)
+func ruleSeparator(src []byte, i int) string {
+	start := i
+	for start > 0 && (src[start-1] == ' ' || src[start-1] == '\t') {
+		start--
+	}
+	if start == 0 || src[start-1] != '\n' {
+		return ""
+	}
+	return "\n" + string(src[start:i])
+}
+
+// parseBrowsers returns the oldest supported version of each browser of the target list
+func parseBrowsers(browsers []string) (map[string]float64, error) {
+	if len(browsers) == 0 {
+		browsers = DefaultBrowsers
+	}
+	targets := make(map[string]float64)
+	for _, browser := range browsers {
+		fields := strings.Fields(strings.ToLower(browser))
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid browser target %q, expected \"name version\"", browser)
+		}
+		version, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid browser target %q: %s", browser, err)
+		}
+		if oldest, ok := targets[fields[0]]; !ok || version < oldest {
+			targets[fields[0]] = version
+		}
+	}
+	return targets, nil
+}
+
+// needed tells if one of the targets needs a prefix
+func (bv browserVersions) needed(targets map[string]float64) bool {
+	for browser, version := range targets {
+		if below, ok := bv[browser]; ok && version < below {
+			return true
+		}
+	}
+	return false
+}
+
+// skipString returns the index of the quote ending the string starting at src[i]
+func skipString(src []byte, i int) int {
+	quote := src[i]
+	for i++; i < len(src) && src[i] != quote; i++ {
+		if src[i] == '\\' {
+			i++
+		}
+	}
+	return i
+}
+
+// splitDeclarations splits a declaration block on its semicolons, they are kept at the end of each declaration
+func splitDeclarations(block []byte) [][]byte {
+	var ret [][]byte
+	start, parenthesis := 0, 0
+	for i := 0; i < len(block); i++ {
+		switch block[i] {
+		case '"', '\'':
+			i = skipString(block, i)
+		case '(':
+			parenthesis++
+		case ')':
+			parenthesis--
+		case ';':
+			if parenthesis == 0 {
+				ret = append(ret, block[start:i+1])
+				start = i + 1
+			}
+		}
+	}
+	return append(ret, block[start:])
+}
+
+// prefixBlock adds the prefixed declarations needed by the targets before the declarations of a block
+// If only is not empty, the only prefixed declarations added are the ones whose property or value starts with it
+func prefixBlock(block []byte, targets map[string]float64, only string) []byte {
+	declarations := splitDeclarations(block)
+	existing := make(map[string]bool)
+	for _, declaration := range declarations {
+		if property, value, ok := parseDeclaration(declaration); ok {
+			existing[property] = true
+			existing[property+":"+value] = true
+		}
+	}
+	var out bytes.Buffer
+	for _, declaration := range declarations {
+		property, value, ok := parseDeclaration(declaration)
+		if ok {
+			colon := bytes.IndexByte(declaration, ':')
+			lead := declaration[:len(declaration)-len(bytes.TrimLeft(declaration, " \t\r\n"))]
+			sep := declaration[colon : colon+1+len(declaration[colon+1:])-len(bytes.TrimLeft(declaration[colon+1:], " \t\r\n"))]
+			for _, prefixed := range prefixDeclaration(property, value, targets) {
+				if prefixed[0] != property && existing[prefixed[0]] || existing[prefixed[0]+":"+prefixed[1]] {
+					continue
+				}
+				if only != "" && !strings.HasPrefix(prefixed[0], only) && !strings.HasPrefix(prefixed[1], only) {
+					continue
+				}
+				out.Write(lead)
+				out.WriteString(prefixed[0])
+				out.Write(sep)
+				out.WriteString(prefixed[1])
+				out.WriteByte(';')
+			}
+		}
+		out.Write(declaration)
+	}
+	return out.Bytes()
+}
+
+// parseDeclaration returns the property and the value of a declaration
+func parseDeclaration(declaration []byte) (string, string, bool) {
+	colon := bytes.IndexByte(declaration, ':')
+	if colon < 0 {
+		return "", "", false
+	}
+	property := strings.ToLower(strings.TrimSpace(string(declaration[:colon])))
+	if len(property) == 0 || strings.ContainsAny(property, " \t\r\n{}$@/") {
+		return "", "", false
+	}
+	value := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(string(declaration[colon+1:])), ";"))
+	return property, value, true
+}
+
+// prefixDeclaration returns the prefixed declarations (property and value) of a declaration needed by the targets
+func prefixDeclaration(property, value string, targets map[string]float64) [][2]string {
+	var ret [][2]string
+	for _, prefix := range propertyPrefixes[property] {
+		if !prefix.browsers.needed(targets) {
+			continue
+		}
+		prefixedValue := value
+		if prefix.values != nil {
+			important := ""
+			if i := strings.Index(prefixedValue, "!"); i >= 0 {
+				prefixedValue, important = strings.TrimSpace(prefixedValue[:i]), prefixedValue[i:]
+			}
+			mapped, ok := prefix.values[prefixedValue]
+			if !ok {
+				continue
+			}
+			prefixedValue = mapped + important
+		}
+		if strings.HasPrefix(prefix.name, "-webkit-transition") && webkitTransform.needed(targets) {
+			prefixedValue = transformValue.ReplaceAllString(prefixedValue, "${1}-webkit-transform")
+		}
+		ret = append(ret, [2]string{prefix.name, prefixedValue})
+	}
+	baseValue, important := value, ""
+	if i := strings.Index(value, "!"); i >= 0 {
+		baseValue, important = strings.TrimSpace(value[:i]), " "+value[i:]
+	}
+	for _, prefix := range valuePrefixes[property][baseValue] {
+		if prefix.browsers.needed(targets) {
+			ret = append(ret, [2]string{property, prefix.value + important})
+		}
+	}
+	return ret
+}
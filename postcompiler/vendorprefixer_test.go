@@ -0,0 +1,63 @@
+package postcompiler
+
+import "testing"
+
+func TestVendorPrefixer(t *testing.T) {
+	tests := []struct {
+		name     string
+		browsers []string
+		src      string
+		want     string
+	}{
+		{"no prefix needed", []string{"chrome 80"}, "a{display:flex;transform:none}", "a{display:flex;transform:none}"},
+		{"transform", []string{"safari 8", "ie 9"}, "a{transform:none}", "a{-webkit-transform:none;-ms-transform:none;transform:none}"},
+		{"flexbox values", []string{"ie 10"}, "a{display:flex;justify-content:space-between}",
+			"a{display:-ms-flexbox;display:flex;-ms-flex-pack:justify;justify-content:space-between}"},
+		{"existing prefix kept", []string{"safari 8"}, "a{-webkit-transform:none;transform:none}", "a{-webkit-transform:none;transform:none}"},
+		{"transition of transform", []string{"safari 6"}, "a{transition:transform 1s}",
+			"a{-webkit-transition:-webkit-transform 1s;transition:transform 1s}"},
+		{"important", []string{"ie 10"}, "a{display:flex !important}", "a{display:-ms-flexbox !important;display:flex !important}"},
+		{"strings and comments", []string{"safari 8"}, "/* a{transform:none} */a{content:\"}\";transform:none}",
+			"/* a{transform:none} */a{content:\"}\";-webkit-transform:none;transform:none}"},
+		{"media query", []string{"safari 8"}, "@media print{a{transform:none}}", "@media print{a{-webkit-transform:none;transform:none}}"},
+		{"keyframes", []string{"safari 8"}, "@keyframes spin{to{transform:rotate(1turn)}}a{animation:spin 1s}",
+			"@-webkit-keyframes spin{to{-webkit-transform:rotate(1turn);transform:rotate(1turn)}}" +
+				"@keyframes spin{to{-webkit-transform:rotate(1turn);transform:rotate(1turn)}}a{-webkit-animation:spin 1s;animation:spin 1s}"},
+		{"keyframes copy keeps webkit prefixes only", []string{"safari 8", "ie 9"}, "@keyframes spin{to{transform:none}}",
+			"@-webkit-keyframes spin{to{-webkit-transform:none;transform:none}}" +
+				"@keyframes spin{to{-webkit-transform:none;-ms-transform:none;transform:none}}"},
+		{"keyframes on their own line", []string{"safari 8"}, "a {}\n  @keyframes fade {\n    to { opacity: 0; }\n  }\n",
+			"a {}\n  @-webkit-keyframes fade {\n    to { opacity: 0; }\n  }\n  @keyframes fade {\n    to { opacity: 0; }\n  }\n"},
+		{"existing webkit keyframes", []string{"safari 8"}, "@-webkit-keyframes fade{to{opacity:0}}@keyframes fade{to{opacity:0}}",
+			"@-webkit-keyframes fade{to{opacity:0}}@keyframes fade{to{opacity:0}}"},
+		{"keyframes not needed", []string{"safari 10"}, "@keyframes fade{to{opacity:0}}", "@keyframes fade{to{opacity:0}}"},
+	}
+	for _, test := range tests {
+		out, err := (&VendorPrefixer{Browsers: test.browsers}).Process([]byte(test.src), "test.css")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if string(out) != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, out, test.want)
+		}
+	}
+}
+
+func TestVendorPrefixerErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		browsers []string
+		src      string
+	}{
+		{"invalid browser", []string{"safari"}, "a{}"},
+		{"invalid version", []string{"safari x"}, "a{}"},
+		{"unterminated comment", nil, "a{} /* b"},
+		{"unterminated keyframes", nil, "@keyframes spin{to{opacity:0}"},
+	}
+	for _, test := range tests {
+		if _, err := (&VendorPrefixer{Browsers: test.browsers}).Process([]byte(test.src), "test.css"); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
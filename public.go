@@ -5,13 +5,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+
+	"github.com/znly/go-sprockets/types"
 )
 
 var (
 	ErrNoPublicPathSet = errors.New("No public path set")
 )
 
-func (s *Sprocket) writeToPublic(assetPath string, FullContent []byte) error {
+func (s *Sprocket) writeToPublic(assetPath string, FullContent []byte, integrity *types.Integrity) error {
 	if len(s.publicPath) == 0 {
 		return ErrNoPublicPathSet
 	}
@@ -24,13 +26,13 @@ func (s *Sprocket) writeToPublic(assetPath string, FullContent []byte) error {
 	if err := ioutil.WriteFile(fullPath, FullContent, os.FileMode(0640)); err != nil {
 		return err
 	}
-	return nil
+	return s.writeToManifest(assetPath, FullContent, integrity)
 }
 
 func (s *Sprocket) Generate(assetPath string) error {
 	if len(s.publicPath) == 0 {
 		return ErrNoPublicPathSet
 	}
-	_, err := s.getAsset(assetPath, true)
+	_, _, err := s.getAsset(assetPath, nil, true)
 	return err
 }
@@ -11,18 +11,65 @@ import (
 	"github.com/znly/go-sprockets/types"
 )
 
-func (s *Sprocket) readAsset(assetPath string, extInfo *types.ExtensionInfo, forceRebuild bool) (fullContent, content []byte, requires []types.RequireInterface, err error) {
+// readAsset reads an asset and its requirements, mimeType is the mime type of the requested asset
+func (s *Sprocket) readAsset(assetPath, mimeType string, extInfo *types.ExtensionInfo, forceRebuild bool) (fullContent, content []byte, requires []types.RequireInterface, err error) {
 	if extInfo.RequirePattern == nil {
-		content, err = s.readAssetContent(assetPath, extInfo)
-		return content, content, nil, err
+		if content, err = s.readAssetContent(assetPath, extInfo); err != nil {
+			return nil, nil, nil, err
+		}
+		if extInfo.FileCompiler != nil {
+			if content, err = extInfo.FileCompiler.Process(content, assetPath); err != nil {
+				return nil, nil, nil, &CompileError{assetPath, err}
+			}
+		}
+		if content, err = s.processFile(assetPath, mimeType, content); err != nil {
+			return nil, nil, nil, err
+		}
+		content = s.rewriteCSSURLs(assetPath, assetPath, mimeType, content)
+		fullContent, needsRuntime := s.wrapModule(assetPath, content)
+		if needsRuntime {
+			fullContent = append([]byte(commonJSRuntime), fullContent...)
+			fullContent = append(fullContent, s.commonJSRequire(assetPath, []string{assetPath})...)
+		}
+		return fullContent, content, nil, nil
+	}
+	dependencyList, contents, content, requires, err := s.readDependencies(assetPath, mimeType, extInfo, forceRebuild, nil)
+	if err != nil {
+		return
 	}
+	var modules []string
+	for _, val := range dependencyList {
+		wrapped, needsRuntime := s.wrapModule(val, contents[val])
+		if needsRuntime {
+			if len(modules) == 0 {
+				fullContent = append(fullContent, commonJSRuntime...)
+			}
+			modules = append(modules, val)
+		}
+		fullContent = append(fullContent, byte('\n'))
+		fullContent = append(fullContent, wrapped...)
+	}
+	if len(modules) > 0 {
+		fullContent = append(fullContent, s.commonJSRequire(assetPath, modules)...)
+	}
+	return
+}
+
+// readDependencies walks the dependency graph of an asset
+// Return the ordered list of the files to bundle and their content processed for mimeType
+// If usedAliases is not nil, it is filled with the alias rule which gave each required file
+func (s *Sprocket) readDependencies(assetPath, mimeType string, extInfo *types.ExtensionInfo, forceRebuild bool, usedAliases map[string]*aliasRule) (dependencyList []string, contents map[string][]byte, content []byte, requires []types.RequireInterface, err error) {
 	graph := dependencygraph.Graph{}
-	curAssetCache := make(map[string][]byte)
-	dependencyList, err := graph.Walk(assetPath, func(curPath, parentPath string, g *dependencygraph.Graph) error {
+	contents = make(map[string][]byte)
+	dependencyList, err = graph.Walk(assetPath, func(curPath, parentPath string, g *dependencygraph.Graph) error {
 		curRequires, curContent, curErr := s.readAssetWithDependencies(curPath, parentPath, forceRebuild)
 		if curErr != nil {
 			return curErr
 		}
+		if curContent, curErr = s.processFile(curPath, mimeType, curContent); curErr != nil {
+			return curErr
+		}
+		curContent = s.rewriteCSSURLs(curPath, assetPath, mimeType, curContent)
 		if curPath == assetPath {
 			content = curContent
 			requires = curRequires
@@ -32,22 +79,20 @@ func (s *Sprocket) readAsset(assetPath string, extInfo *types.ExtensionInfo, for
 			if err != nil {
 				return err
 			}
+			if rf, ok := r.(*requireFile); ok && rf.alias != nil && usedAliases != nil {
+				for _, requiredFile := range requiredFiles {
+					usedAliases[requiredFile] = rf.alias
+				}
+			}
 			selfIndex := sort.SearchStrings(requiredFiles, curPath)
 			if selfIndex < len(requiredFiles) && requiredFiles[selfIndex] == curPath {
 				requiredFiles = append(requiredFiles[:selfIndex], requiredFiles[selfIndex+1:]...)
 			}
 			g.AddChildrens(curPath, requiredFiles...)
 		}
-		curAssetCache[curPath] = curContent
+		contents[curPath] = curContent
 		return nil
 	})
-	if err != nil {
-		return
-	}
-	for _, val := range dependencyList {
-		fullContent = append(fullContent, byte('\n'))
-		fullContent = append(fullContent, curAssetCache[val]...)
-	}
 	return
 }
 
@@ -57,7 +102,7 @@ func (s *Sprocket) readAssetWithDependencies(argAssetPath, parentPath string, fo
 	if err != nil {
 		return nil, nil, err
 	}
-	if cacheKey, err = s.assetsCache.GenerateCacheKey(assetPath); err != nil {
+	if cacheKey, err = s.generateCacheKey(assetPath); err != nil {
 		return nil, nil, ErrNotFound
 	}
 	if forceRebuild == false {
@@ -71,10 +116,10 @@ func (s *Sprocket) readAssetWithDependencies(argAssetPath, parentPath string, fo
 	if err != nil {
 		return
 	}
-	if extInfo.RequirePattern == nil {
-		return
+	var header []byte
+	if extInfo.RequirePattern != nil {
+		header = extInfo.RequirePattern.Head.Find(content)
 	}
-	header := extInfo.RequirePattern.Head.Find(content)
 	if len(header) != 0 {
 		newheader := header
 		dirPath := filepath.Dir(assetPath)
@@ -95,7 +140,8 @@ func (s *Sprocket) readAssetWithDependencies(argAssetPath, parentPath string, fo
 			} else if bytes.Equal(ret[0][2], []byte("_directory")) {
 				requires = append(requires, &requireDirectory{string(ret[0][3]), dirPath})
 			} else {
-				requires = append(requires, &requireFile{string(ret[0][3]), dirPath})
+				path, alias := s.aliases.apply(string(ret[0][3]))
+				requires = append(requires, &requireFile{path, dirPath, alias})
 			}
 		}
 
@@ -104,10 +150,11 @@ func (s *Sprocket) readAssetWithDependencies(argAssetPath, parentPath string, fo
 	if extInfo.FileCompiler != nil {
 		content, err = extInfo.FileCompiler.Process(content, assetPath)
 		if err != nil {
+			err = &CompileError{assetPath, err}
 			return
 		}
 	}
-	s.assetsCache.WriteToCache(cacheKey, nil, content, requires, extInfo)
+	s.assetsCache.WriteToCache(cacheKey, nil, content, requires, extInfo, nil)
 	return
 }
 
@@ -116,6 +163,9 @@ func (s *Sprocket) readAssetContent(assetPath string, extInfo *types.ExtensionIn
 	if err != nil {
 		return nil, ErrNotFound
 	}
+	if content, err = s.preprocessTemplate(assetPath, content); err != nil {
+		return nil, err
+	}
 	for _, f := range extInfo.ContentTreatment {
 		content, err = f.Process(content, assetPath)
 		if err != nil {
@@ -0,0 +1,164 @@
+package sprockets
+
+import (
+	"sort"
+
+	"github.com/znly/go-sprockets/types"
+)
+
+// processorRegistry holds the processors registered by mime type
+// they run in addition of the ExtensionInfo treatments:
+//   - for each file: preprocessors of its mime type, then the transformers from its mime type
+//     to the mime type of the asset, then postprocessors of the mime type of the asset
+//   - for the bundle: bundle processors after the BundleCompiler, then compressors after
+//     the PostCompileContentTreatment (only in production mode)
+type processorRegistry struct {
+	transformers     map[string]map[string]types.ContentTreatmentInterface
+	preprocessors    map[string][]types.ContentTreatmentInterface
+	postprocessors   map[string][]types.ContentTreatmentInterface
+	bundleProcessors map[string][]types.ContentTreatmentInterface
+	compressors      map[string][]types.ContentTreatmentInterface
+	// generation changes with the settings changing the built contents (processors, defines...), it is part of the cache keys
+	generation int
+}
+
+func newProcessorRegistry() *processorRegistry {
+	return &processorRegistry{
+		transformers:     make(map[string]map[string]types.ContentTreatmentInterface),
+		preprocessors:    make(map[string][]types.ContentTreatmentInterface),
+		postprocessors:   make(map[string][]types.ContentTreatmentInterface),
+		bundleProcessors: make(map[string][]types.ContentTreatmentInterface),
+		compressors:      make(map[string][]types.ContentTreatmentInterface),
+	}
+}
+
+// RegisterTransformer will add a transformer turning a file of the mime type from into the mime type to
+// Transformers are chained: with "text/coffeescript" to "application/javascript" registered,
+// a ".coffee" file will be transformed when bundled into a ".js" asset
+// (remove the ".coffee" FileCompiler set by NewWithDefault if you use a transformer for it)
+func (s *Sprocket) RegisterTransformer(from, to string, transformer types.ContentTreatmentInterface) {
+	if _, ok := s.processors.transformers[from]; !ok {
+		s.processors.transformers[from] = make(map[string]types.ContentTreatmentInterface)
+	}
+	s.processors.transformers[from][to] = transformer
+	s.processors.generation++
+}
+
+// RegisterPreprocessor will add a processor that will be use on each file of this mime type before the transformers
+func (s *Sprocket) RegisterPreprocessor(mimeType string, preprocessor types.ContentTreatmentInterface) {
+	s.processors.preprocessors[mimeType] = append(s.processors.preprocessors[mimeType], preprocessor)
+	s.processors.generation++
+}
+
+// RegisterPostprocessor will add a processor that will be use on each file bundled into an asset of this mime type after the transformers
+func (s *Sprocket) RegisterPostprocessor(mimeType string, postprocessor types.ContentTreatmentInterface) {
+	s.processors.postprocessors[mimeType] = append(s.processors.postprocessors[mimeType], postprocessor)
+	s.processors.generation++
+}
+
+// RegisterBundleProcessor will add a processor that will be use on the full content of an asset of this mime type after the BundleCompiler
+func (s *Sprocket) RegisterBundleProcessor(mimeType string, bundleProcessor types.ContentTreatmentInterface) {
+	s.processors.bundleProcessors[mimeType] = append(s.processors.bundleProcessors[mimeType], bundleProcessor)
+	s.processors.generation++
+}
+
+// RegisterCompressor will add a processor that will be use on the full content of an asset of this mime type at the end of the pipeline
+// Compressors only run in production mode (see SetProduction)
+func (s *Sprocket) RegisterCompressor(mimeType string, compressor types.ContentTreatmentInterface) {
+	s.processors.compressors[mimeType] = append(s.processors.compressors[mimeType], compressor)
+	s.processors.generation++
+}
+
+// getMimeType returns the mime type declared for the extension of assetPath
+func (s *Sprocket) getMimeType(assetPath string) string {
+	return s.getExtensionInfoOrDefault(assetExt(assetPath)).MimeType
+}
+
+// transformerChain returns the shortest chain of transformers from a mime type to another
+// nil if there is none
+func (pr *processorRegistry) transformerChain(from, to string) []types.ContentTreatmentInterface {
+	if from == to || len(from) == 0 || len(to) == 0 {
+		return nil
+	}
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		targets := make([]string, 0, len(pr.transformers[cur]))
+		for target := range pr.transformers[cur] {
+			targets = append(targets, target)
+		}
+		sort.Strings(targets)
+		for _, target := range targets {
+			if _, seen := previous[target]; seen {
+				continue
+			}
+			previous[target] = cur
+			if target != to {
+				queue = append(queue, target)
+				continue
+			}
+			var chain []types.ContentTreatmentInterface
+			for t := to; t != from; t = previous[t] {
+				chain = append([]types.ContentTreatmentInterface{pr.transformers[previous[t]][t]}, chain...)
+			}
+			return chain
+		}
+	}
+	return nil
+}
+
+// processFile runs the preprocessors, transformers and postprocessors of the registry on a file
+// mimeType is the mime type of the asset the file is bundled into
+// The result is cached alongside the file cache
+func (s *Sprocket) processFile(assetPath, mimeType string, content []byte) ([]byte, error) {
+	fileMimeType := s.getMimeType(assetPath)
+	var processors []types.ContentTreatmentInterface
+	processors = append(processors, s.processors.preprocessors[fileMimeType]...)
+	processors = append(processors, s.processors.transformerChain(fileMimeType, mimeType)...)
+	processors = append(processors, s.processors.postprocessors[mimeType]...)
+	if len(processors) == 0 {
+		return content, nil
+	}
+	cacheKey, err := s.generateCacheKey(assetPath)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	cacheKey.AssetPath += "\x00" + mimeType
+	if processed, _, _, _, hit := s.assetsCache.ReadFromCache(cacheKey); hit {
+		return processed, nil
+	}
+	for _, f := range processors {
+		if content, err = f.Process(content, assetPath); err != nil {
+			return nil, &CompileError{assetPath, err}
+		}
+	}
+	s.assetsCache.WriteToCache(cacheKey, nil, content, nil, nil, nil)
+	return content, nil
+}
+
+// processBundle runs the bundle processors of the registry on the full content of an asset
+func (s *Sprocket) processBundle(assetPath, mimeType string, fullContent []byte) (ret []byte, err error) {
+	ret = fullContent
+	for _, f := range s.processors.bundleProcessors[mimeType] {
+		if ret, err = f.Process(ret, assetPath); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// compressBundle runs the compressors of the registry on the full content of an asset in production mode
+func (s *Sprocket) compressBundle(assetPath, mimeType string, fullContent []byte) (ret []byte, err error) {
+	ret = fullContent
+	if !s.production {
+		return
+	}
+	for _, f := range s.processors.compressors[mimeType] {
+		if ret, err = f.Process(ret, assetPath); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
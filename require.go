@@ -22,6 +22,7 @@ type requireDirectory struct {
 type requireFile struct {
 	Path    string
 	BaseDir string
+	alias   *aliasRule // the alias rule which gave Path, nil if none
 }
 
 // GetList is needed for RequireInterface
@@ -47,7 +48,7 @@ func (rt *requireTree) GetList(extInfo *types.ExtensionInfo) (requiredFiles []st
 		if f.IsDir() {
 			return nil
 		}
-		curExt := filepath.Ext(walkpath)
+		curExt := assetExt(walkpath)
 		if curExt == extInfo.CurrentExtension {
 			requiredFiles = append(requiredFiles, walkpath)
 			return nil
@@ -92,6 +93,11 @@ func (rd *requireDirectory) GetList(extInfo *types.ExtensionInfo) (requiredFiles
 		if err != nil {
 			return nil, 0, err
 		}
+		templates, err := filepath.Glob(filepath.Join(finalPath, "*"+e.Value+templateExt))
+		if err != nil {
+			return nil, 0, err
+		}
+		files = append(files, templates...)
 		for _, file := range files {
 			f, err := os.Stat(file)
 			if err != nil {
@@ -8,9 +8,12 @@ import (
 	"github.com/znly/go-sprockets/types"
 )
 
+// jstExt is the extension the rails conventions put before the one of the templates (ie: user.jst.ejs)
+const jstExt = ".jst"
+
 func resolveExt(ei *types.ExtensionInfo, argAssetPath, argExt string) (string, string, bool) {
-	if isFileExist(argAssetPath) {
-		return argAssetPath, argExt, true
+	if assetPath, ok := existingAsset(argAssetPath); ok {
+		return assetPath, argExt, true
 	}
 	if ei.AlterExts.Find(argExt) != nil {
 		for e := ei.AlterExts.Front(); e != nil; e = e.Next() {
@@ -19,7 +22,7 @@ func resolveExt(ei *types.ExtensionInfo, argAssetPath, argExt string) (string, s
 				continue
 			}
 			alterPath := strings.Replace(argAssetPath, argExt, alterExt, 1)
-			if isFileExist(alterPath) {
+			if alterPath, ok := existingAlterAsset(alterPath, alterExt); ok {
 				return alterPath, alterExt, true
 			}
 		}
@@ -30,16 +33,25 @@ func resolveExt(ei *types.ExtensionInfo, argAssetPath, argExt string) (string, s
 			continue
 		}
 		alterPath := argAssetPath + alterExt
-		if isFileExist(alterPath) {
+		if alterPath, ok := existingAlterAsset(alterPath, alterExt); ok {
 			return alterPath, alterExt, true
 		}
 	}
 	return "", "", false
 }
 
+// existingAlterAsset returns the existing asset of path, ending with alterExt, or of the same path with
+// jstExt before alterExt (ie: templates/user.jst.ejs for templates/user.ejs)
+func existingAlterAsset(path, alterExt string) (string, bool) {
+	if path, ok := existingAsset(path); ok {
+		return path, true
+	}
+	return existingAsset(strings.TrimSuffix(path, alterExt) + jstExt + alterExt)
+}
+
 func resolvePath(ei *types.ExtensionInfo, assetPath string, baseDir string) (string, string, error) {
-	ext := filepath.Ext(assetPath)
-	//TODO: add a security to stay in certain directories (CHROOT LIKE)
+	ext := assetExt(assetPath)
+	// ServeHTTP keeps the served assets in the assets directories, the requirements may leave them with relative paths
 	if strings.HasPrefix(assetPath, ".") {
 		if baseDir == "" {
 			return "", "", ErrNotFound
@@ -72,6 +84,13 @@ func resolvePath(ei *types.ExtensionInfo, assetPath string, baseDir string) (str
 				ext = curExt
 			}
 		}
+		for i := 0; !found && i < len(ei.PathResolvers); i++ {
+			if curAssetPath, curExt, ok := ei.PathResolvers[i].Resolve(assetPath, ei); ok {
+				found = true
+				assetPath = curAssetPath
+				ext = curExt
+			}
+		}
 		if !found {
 			return "", "", ErrNotFound
 		}
@@ -100,23 +119,41 @@ func (s *Sprocket) checkPublicPath(assetPath string, baseDir string) (string, er
 // Return Pathfound, Extension found, or an error
 // It will search base on path then extension
 func (s *Sprocket) resolvePath(assetPath string, baseDir string, forceRebuild bool) (string, *types.ExtensionInfo, error) {
+	realPath, extInfo, _, err := s.resolveAliasedPath(assetPath, baseDir, forceRebuild)
+	return realPath, extInfo, err
+}
+
+// resolveAliasedPath is resolvePath also returning the alias rule applied to assetPath (nil if none)
+func (s *Sprocket) resolveAliasedPath(assetPath string, baseDir string, forceRebuild bool) (string, *types.ExtensionInfo, *aliasRule, error) {
 	var err error
-	ext := filepath.Ext(assetPath)
+	ext := assetExt(assetPath)
 	extInfo := s.getExtensionInfoOrDefault(ext)
 	if forceRebuild == false {
 		assetPublicPath, _ := s.checkPublicPath(assetPath, baseDir)
 		if len(assetPublicPath) > 0 {
-			return assetPublicPath, extInfo, nil
+			return assetPublicPath, extInfo, nil, nil
 		}
 	}
-	assetPath, ext, err = resolvePath(extInfo, assetPath, baseDir)
+	aliasedPath, alias := s.aliases.apply(assetPath)
+	assetPath, ext, err = resolvePath(extInfo, aliasedPath, baseDir)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
-	return assetPath, s.getExtensionInfoOrDefault(ext), nil
+	return assetPath, s.getExtensionInfoOrDefault(ext), alias, nil
 }
 
 func isFileExist(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// existingAsset returns path, or path with the ".tmpl" extension, if the file exists
+func existingAsset(path string) (string, bool) {
+	if isFileExist(path) {
+		return path, true
+	}
+	if isFileExist(path + templateExt) {
+		return path + templateExt, true
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package sprockets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveJSTTemplates(t *testing.T) {
+	assetsPath, err := ioutil.TempDir("", "sprockets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(assetsPath)
+	templates := filepath.Join(assetsPath, "javascripts", "templates")
+	if err := os.MkdirAll(templates, 0750); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"user.jst.ejs", "item.jst.hbs", "list.mustache"} {
+		if err := ioutil.WriteFile(filepath.Join(templates, name), []byte("<p></p>"), 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := NewWithDefault(assetsPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"templates/user", "user.jst.ejs"},
+		{"templates/user.js", "user.jst.ejs"},
+		{"templates/user.jst", "user.jst.ejs"},
+		{"templates/user.jst.ejs", "user.jst.ejs"},
+		{"templates/item", "item.jst.hbs"},
+		{"templates/item.js", "item.jst.hbs"},
+		{"templates/list", "list.mustache"},
+	}
+	jsExtInfo := s.getExtensionInfoOrDefault(".js")
+	for _, test := range tests {
+		realPath, _, err := resolvePath(jsExtInfo, test.path, "")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.path, err)
+			continue
+		}
+		if filepath.Base(realPath) != test.want {
+			t.Errorf("%s: got %s, want %s", test.path, realPath, test.want)
+		}
+	}
+	if _, _, err := resolvePath(jsExtInfo, "templates/missing", ""); err != ErrNotFound {
+		t.Errorf("templates/missing: got error %v, want %v", err, ErrNotFound)
+	}
+}
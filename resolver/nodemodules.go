@@ -0,0 +1,166 @@
+// Package resolver holds the PathResolver to find assets outside of the paths of the extensions
+package resolver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/znly/go-sprockets/types"
+)
+
+// DefaultPackageFields are the package.json fields giving the entry file of a package, in order of preference
+var DefaultPackageFields = []string{"browser", "style", "main"}
+
+// NodeModulesResolver is here to find the assets in the npm packages of node_modules directories
+// "jquery" resolves to the entry file of [Dir]/jquery, given by the first of the package.json Fields
+// whose extension is one of the requested one (ie: "style" for a stylesheet), or to its index file.
+// "jquery/dist/jquery.min" resolves to a file of the package.
+// Add it to the extensions which can require a package (see Sprocket.AddPathResolver):
+//
+//	s.AddPathResolver(".js", &resolver.NodeModulesResolver{Dirs: []string{"node_modules"}})
+type NodeModulesResolver struct {
+	Dirs   []string
+	Fields []string // DefaultPackageFields if empty
+}
+
+// Resolve to implement PathResolver
+func (nmr *NodeModulesResolver) Resolve(assetPath string, extInfo *types.ExtensionInfo) (string, string, bool) {
+	if len(assetPath) == 0 || assetPath[0] == '.' || filepath.IsAbs(assetPath) {
+		return "", "", false
+	}
+	var exts []string
+	for e := extInfo.AlterExts.Front(); e != nil; e = e.Next() {
+		exts = append(exts, e.Value)
+	}
+	name, subPath := splitPackagePath(filepath.ToSlash(assetPath))
+	names := []string{name}
+	if len(subPath) == 0 {
+		// "jquery.js" is the package "jquery"
+		if trimmed := strings.TrimSuffix(name, filepath.Ext(name)); trimmed != name {
+			names = append(names, trimmed)
+		}
+	}
+	for _, dir := range nmr.Dirs {
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			packageDir := filepath.Join(dir, filepath.FromSlash(name))
+			if !isInDir(dir, packageDir) {
+				continue
+			}
+			if info, err := os.Stat(packageDir); err != nil || !info.IsDir() {
+				continue
+			}
+			if len(subPath) > 0 {
+				filePath := filepath.Join(packageDir, filepath.FromSlash(subPath))
+				if !isInDir(dir, filePath) {
+					continue
+				}
+				if path, ext, ok := findFile(filePath, exts); ok {
+					return path, ext, true
+				}
+				continue
+			}
+			for _, entry := range nmr.entries(packageDir) {
+				entryPath := filepath.Join(packageDir, filepath.FromSlash(entry))
+				if !isInDir(dir, entryPath) {
+					continue
+				}
+				if path, ext, ok := findFile(entryPath, exts); ok {
+					return path, ext, true
+				}
+			}
+			if path, ext, ok := findFile(filepath.Join(packageDir, "index"), exts); ok {
+				return path, ext, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// isInDir tells if the joined path is still in dir (ie: "../" segments do not leave the node_modules directory)
+func isInDir(dir, path string) bool {
+	relPath, err := filepath.Rel(dir, path)
+	return err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
+
+// LogicalPath to implement PathResolver
+// The files of a package are given as package/path/of/the/file (ie: jquery/dist/jquery.js)
+func (nmr *NodeModulesResolver) LogicalPath(realPath string) (string, bool) {
+	for _, dir := range nmr.Dirs {
+		if absDir, err := filepath.Abs(dir); err == nil {
+			if newDir, err := filepath.EvalSymlinks(absDir); err == nil {
+				absDir = newDir
+			}
+			if relPath, err := filepath.Rel(absDir, realPath); err == nil && !strings.HasPrefix(relPath, "..") {
+				return filepath.ToSlash(relPath), true
+			}
+		}
+	}
+	return "", false
+}
+
+// splitPackagePath returns the name of the package (ie: "jquery" or "@scope/name") and the path in it
+func splitPackagePath(assetPath string) (string, string) {
+	parts := strings.SplitN(assetPath, "/", 3)
+	if strings.HasPrefix(assetPath, "@") && len(parts) > 1 {
+		return parts[0] + "/" + parts[1], strings.Join(parts[2:], "/")
+	}
+	return parts[0], strings.Join(parts[1:], "/")
+}
+
+// entries returns the entry files declared in the package.json of packageDir, in the order of the fields
+func (nmr *NodeModulesResolver) entries(packageDir string) []string {
+	content, err := ioutil.ReadFile(filepath.Join(packageDir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if json.Unmarshal(content, &fields) != nil {
+		return nil
+	}
+	names := nmr.Fields
+	if len(names) == 0 {
+		names = DefaultPackageFields
+	}
+	var ret []string
+	for _, name := range names {
+		// the browser field may also be an object of replacements, which is not supported
+		if entry, ok := fields[name].(string); ok && len(entry) > 0 {
+			ret = append(ret, entry)
+		}
+	}
+	return ret
+}
+
+// findFile returns the file of path with one of the extensions exts: path itself, path with one of the extensions added,
+// or the index file of the directory path
+func findFile(path string, exts []string) (string, string, bool) {
+	ext := filepath.Ext(path)
+	for _, e := range exts {
+		if e == ext && isFile(path) {
+			return path, ext, true
+		}
+	}
+	for _, e := range exts {
+		if isFile(path + e) {
+			return path + e, e, true
+		}
+	}
+	for _, e := range exts {
+		if index := filepath.Join(path, "index"+e); isFile(index) {
+			return index, e, true
+		}
+	}
+	return "", "", false
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,37 @@
+package sprockets
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrNoBundleCompiler is returned when variables are given for an asset which is not compiled by a bundlecompiler.ScssSassCompiler
+var ErrNoBundleCompiler = errors.New("No sass bundle compiler to give the variables to")
+
+// GetAssetWithVars will return the asset full content compiled with the sass variables vars (ie: {"$primary": "#f00"})
+// declared before its bundle, which is then cached separately for each set of variables
+// The variants are not written into the public path
+func (s *Sprocket) GetAssetWithVars(assetPath string, vars map[string]string) ([]byte, error) {
+	fullContent, _, err := s.getAsset(assetPath, vars, false)
+	if compileErr, ok := err.(*CompileError); ok && s.errorOverlay {
+		if overlay := errorOverlay(assetPath, compileErr); overlay != nil {
+			return overlay, nil
+		}
+	}
+	return fullContent, err
+}
+
+// sassVariables returns the sass declarations of vars sorted by name, the "$" of the names being optional
+func sassVariables(vars map[string]string) []byte {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	declarations := make([]string, len(names))
+	for i, name := range names {
+		declarations[i] = "$" + strings.TrimPrefix(name, "$") + ": " + vars[name] + ";\n"
+	}
+	return []byte(strings.Join(declarations, ""))
+}
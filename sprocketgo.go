@@ -4,12 +4,14 @@ import (
 	"path/filepath"
 
 	"github.com/znly/go-sprockets/assetscache"
+	"github.com/znly/go-sprockets/bundlecompiler"
 	"github.com/znly/go-sprockets/types"
 )
 
 // New creates a new Sprocket pipeline
 // if publicPath is an empty string, Asset will be compiled and cached in memory
 // if publicPath is not empty Files will be served from the public path and builded only if necessary
+// and the production mode is enabled (see SetProduction)
 func New(assetsPath, publicPath string) (s *Sprocket, err error) {
 	s = &Sprocket{}
 	s.assetsPath, err = filepath.Abs(assetsPath)
@@ -20,6 +22,9 @@ func New(assetsPath, publicPath string) (s *Sprocket, err error) {
 	s.PushFrontDefaultPath(s.assetsPath)
 	s.extInfos = make(map[string]*types.ExtensionInfo)
 	s.assetsCache = assetscache.New()
+	s.manifest = newManifest()
+	s.processors = newProcessorRegistry()
+	s.aliases = newAliasTable()
 	if len(publicPath) == 0 {
 		return
 	}
@@ -27,50 +32,123 @@ func New(assetsPath, publicPath string) (s *Sprocket, err error) {
 	if err != nil {
 		return nil, err
 	}
+	s.production = true
+	if err = s.readManifest(); err != nil {
+		return nil, err
+	}
 	return
 }
 
-func (s *Sprocket) getAsset(assetPath string, forceRebuild bool) ([]byte, error) {
-	realAssetPath, extInfo, err := s.resolvePath(assetPath, "", forceRebuild)
+// SetProduction enables or disables the production mode, enabled by New when a public path is set
+// In production mode the compressors run and SetJSDefines drops the console calls if asked to
+func (s *Sprocket) SetProduction(enabled bool) {
+	s.production = enabled
+	if s.jsDefiner != nil {
+		s.jsDefiner.DropConsole = s.jsDropConsole && enabled
+	}
+	s.processors.generation++
+}
+
+// getAsset builds the full content of an asset, vars are the sass variables to declare before its bundle (may be nil)
+func (s *Sprocket) getAsset(assetPath string, vars map[string]string, forceRebuild bool) ([]byte, *types.Integrity, error) {
+	// the variants are built from the sources, not from the public file
+	realAssetPath, extInfo, err := s.resolvePath(assetPath, "", forceRebuild || len(vars) > 0)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var cacheKey *assetscache.AssetCacheKey
-	if cacheKey, err = s.assetsCache.GenerateCacheKey(realAssetPath); err != nil {
-		return nil, err
+	if cacheKey, err = s.generateCacheKey(realAssetPath); err != nil {
+		return nil, nil, err
+	}
+	var variables []byte
+	if len(vars) > 0 {
+		if _, ok := extInfo.BundleCompiler.(*bundlecompiler.ScssSassCompiler); !ok {
+			return nil, nil, ErrNoBundleCompiler
+		}
+		variables = sassVariables(vars)
+		cacheKey.Variant = hexDigest(variables)
 	}
 	if forceRebuild == false {
 		if cachedfullContent, err := s.assetsCache.GetFullCache(cacheKey); cachedfullContent != nil || err != nil {
-			return cachedfullContent, err
+			return cachedfullContent, s.assetsCache.GetIntegrity(cacheKey), err
 		}
 	}
-	fullContent, content, requires, err := s.readAsset(realAssetPath, extInfo, forceRebuild)
+	mimeType := s.getMimeType(assetPath)
+	fullContent, content, requires, err := s.readAsset(realAssetPath, mimeType, extInfo, forceRebuild)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	var imports []string
 	if extInfo.BundleCompiler != nil {
-		fullContent, err = extInfo.BundleCompiler.Process(fullContent, realAssetPath)
+		fullContent = append(variables, fullContent...)
+		fullContent, imports, err = s.bundle(realAssetPath, extInfo, fullContent)
 		if err != nil {
-			return nil, err
+			return nil, nil, &CompileError{realAssetPath, err}
 		}
 	}
+	fullContent, inlined := s.inlineDataURIs(realAssetPath, mimeType, fullContent)
+	if fullContent, err = s.processBundle(realAssetPath, mimeType, fullContent); err != nil {
+		return nil, nil, err
+	}
 	for _, f := range extInfo.PostCompileContentTreatment {
 		fullContent, err = f.Process(fullContent, realAssetPath)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
-	s.assetsCache.WriteToCache(cacheKey, fullContent, content, requires, extInfo)
+	if fullContent, err = s.compressBundle(realAssetPath, mimeType, fullContent); err != nil {
+		return nil, nil, err
+	}
+	integrity := types.NewIntegrity(fullContent)
+	s.assetsCache.WriteToCache(cacheKey, fullContent, content, requires, extInfo, integrity)
+	s.assetsCache.SetDependencies(cacheKey, append(imports, inlined...))
+	if len(vars) > 0 {
+		return fullContent, integrity, nil
+	}
 	if forceRebuild == true {
-		return fullContent, s.writeToPublic(assetPath, fullContent)
+		return fullContent, integrity, s.writeToPublic(assetPath, fullContent, integrity)
 	}
 	go func() {
-		s.writeToPublic(assetPath, fullContent)
+		s.writeToPublic(assetPath, fullContent, integrity)
 	}()
-	return fullContent, nil
+	return fullContent, integrity, nil
+}
+
+// generateCacheKey returns the cache key of an asset file built with the current settings of the sprocket
+func (s *Sprocket) generateCacheKey(assetPath string) (*assetscache.AssetCacheKey, error) {
+	key, err := s.assetsCache.GenerateCacheKey(assetPath)
+	if err != nil {
+		return nil, err
+	}
+	key.Generation = s.processors.generation
+	return key, nil
 }
 
 // GetAsset will return the asset full content (with all its requirement) or an error if an error occured
 func (s *Sprocket) GetAsset(assetPath string) ([]byte, error) {
-	return s.getAsset(assetPath, false)
+	content, _, err := s.getAsset(assetPath, nil, false)
+	if compileErr, ok := err.(*CompileError); ok && s.errorOverlay {
+		if overlay := errorOverlay(assetPath, compileErr); overlay != nil {
+			return overlay, nil
+		}
+	}
+	return content, err
+}
+
+// Integrity will return the Subresource Integrity digests of the asset full content or an error if an error occured
+// When the asset is in the manifest, they are the digests of its digest file (the one asset_path links to)
+func (s *Sprocket) Integrity(assetPath string) (*types.Integrity, error) {
+	if digestPath, ok := s.GetDigestPath(assetPath); ok {
+		return s.getDigestIntegrity(digestPath)
+	}
+	return s.buildIntegrity(assetPath)
+}
+
+// buildIntegrity returns the Subresource Integrity digests of the asset built from its sources
+func (s *Sprocket) buildIntegrity(assetPath string) (*types.Integrity, error) {
+	_, integrity, err := s.getAsset(assetPath, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	return integrity, nil
 }
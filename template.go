@@ -0,0 +1,115 @@
+package sprockets
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+
+	"github.com/znly/go-sprockets/types"
+)
+
+// FuncMap returns the html/template helpers of the sprocket:
+//   - asset_path: the url of an asset, using its digest path when found in the manifest
+//   - javascript_include_tag: script tags for the given ".js" assets
+//   - stylesheet_link_tag: link tags for the given ".css" assets
+//   - image_tag: an img tag, followed by pairs of attribute name and value
+//   - asset_data_url: the content of an asset as a base64 data url
+//
+// urlPrefix is the url where the sprocket is served (ie: "/assets")
+// In debug mode, javascript_include_tag and stylesheet_link_tag emit one tag per bundled file
+// If withIntegrity is true, the script and link tags will have an integrity attribute (not in debug mode)
+func (s *Sprocket) FuncMap(urlPrefix string, withIntegrity bool) template.FuncMap {
+	th := &templateHelpers{s, strings.TrimSuffix(urlPrefix, "/"), withIntegrity}
+	return template.FuncMap{
+		"asset_path":             th.assetPath,
+		"javascript_include_tag": th.javascriptIncludeTag,
+		"stylesheet_link_tag":    th.stylesheetLinkTag,
+		"image_tag":              th.imageTag,
+		"asset_data_url":         th.assetDataURL,
+	}
+}
+
+type templateHelpers struct {
+	s             *Sprocket
+	urlPrefix     string
+	withIntegrity bool
+}
+
+func (th *templateHelpers) url(assetPath string) string {
+	return th.urlPrefix + "/" + strings.TrimPrefix(assetPath, "/")
+}
+
+func (th *templateHelpers) assetPath(assetPath string) string {
+	if digestPath, ok := th.s.GetDigestPath(assetPath); ok {
+		return th.url(digestPath)
+	}
+	return th.url(assetPath)
+}
+
+// tags build one tag per asset, format being given the url and the extra attributes
+func (th *templateHelpers) tags(ext, format string, assetPaths []string) (template.HTML, error) {
+	var ret []string
+	for _, assetPath := range assetPaths {
+		if filepath.Ext(assetPath) != ext {
+			assetPath += ext
+		}
+		if th.s.debug {
+			debugAssets, err := th.s.GetDebugAssets(assetPath)
+			if err != nil {
+				return "", err
+			}
+			for _, debugAsset := range debugAssets {
+				ret = append(ret, fmt.Sprintf(format, template.HTMLEscapeString(th.url(debugAsset.URL)), ""))
+			}
+			continue
+		}
+		// the url and the integrity must describe the same file: the digest file if any, else the built asset
+		url := th.url(assetPath)
+		digestPath, hasDigest := th.s.GetDigestPath(assetPath)
+		if hasDigest {
+			url = th.url(digestPath)
+		}
+		attrs := ""
+		if th.withIntegrity {
+			var integrity *types.Integrity
+			var err error
+			if hasDigest {
+				integrity, err = th.s.getDigestIntegrity(digestPath)
+			} else {
+				integrity, err = th.s.buildIntegrity(assetPath)
+			}
+			if err != nil {
+				return "", err
+			}
+			attrs = ` integrity="` + integrity.SHA384 + `" crossorigin="anonymous"`
+		}
+		ret = append(ret, fmt.Sprintf(format, template.HTMLEscapeString(url), attrs))
+	}
+	return template.HTML(strings.Join(ret, "\n")), nil
+}
+
+func (th *templateHelpers) assetDataURL(assetPath string) (template.URL, error) {
+	dataURL, err := th.AssetDataURL(assetPath)
+	return template.URL(dataURL), err
+}
+
+func (th *templateHelpers) javascriptIncludeTag(assetPaths ...string) (template.HTML, error) {
+	return th.tags(".js", `<script src="%s"%s></script>`, assetPaths)
+}
+
+func (th *templateHelpers) stylesheetLinkTag(assetPaths ...string) (template.HTML, error) {
+	return th.tags(".css", `<link rel="stylesheet" href="%s"%s>`, assetPaths)
+}
+
+func (th *templateHelpers) imageTag(assetPath string, attrs ...string) (template.HTML, error) {
+	if len(attrs)%2 != 0 {
+		return "", errors.New("image_tag: attributes must be pairs of name and value")
+	}
+	ret := `<img src="` + template.HTMLEscapeString(th.assetPath(assetPath)) + `"`
+	for i := 0; i < len(attrs); i += 2 {
+		ret += " " + template.HTMLEscapeString(attrs[i]) + `="` + template.HTMLEscapeString(attrs[i+1]) + `"`
+	}
+	return template.HTML(ret + ">"), nil
+}
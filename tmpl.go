@@ -0,0 +1,55 @@
+package sprockets
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateExt is the extension of the assets preprocessed with text/template (ie: config.js.tmpl, theme.scss.tmpl)
+const templateExt = ".tmpl"
+
+// assetExt returns the extension of an asset, the one before ".tmpl" for the preprocessed assets
+func assetExt(assetPath string) string {
+	return filepath.Ext(strings.TrimSuffix(assetPath, templateExt))
+}
+
+// SetTemplateData set the data given to the ".tmpl" assets
+// urlPrefix is the url where the sprocket is served (ie: "/assets"), used by the asset_path function
+func (s *Sprocket) SetTemplateData(urlPrefix string, data map[string]interface{}) {
+	s.templateURLPrefix = strings.TrimSuffix(urlPrefix, "/")
+	s.templateData = data
+	s.processors.generation++
+}
+
+// AddTemplateFuncs add functions to the ".tmpl" assets, in addition to asset_path and asset_data_url
+func (s *Sprocket) AddTemplateFuncs(funcs template.FuncMap) {
+	if s.templateFuncs == nil {
+		s.templateFuncs = template.FuncMap{}
+	}
+	for name, f := range funcs {
+		s.templateFuncs[name] = f
+	}
+	s.processors.generation++
+}
+
+// preprocessTemplate runs text/template on the content of a ".tmpl" asset
+func (s *Sprocket) preprocessTemplate(assetPath string, content []byte) ([]byte, error) {
+	if !strings.HasSuffix(assetPath, templateExt) {
+		return content, nil
+	}
+	th := &templateHelpers{s, s.templateURLPrefix, false}
+	tmpl, err := template.New(filepath.Base(assetPath)).Funcs(template.FuncMap{
+		"asset_path":     th.AssetPath,
+		"asset_data_url": th.AssetDataURL,
+	}).Funcs(s.templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, &CompileError{assetPath, err}
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, s.templateData); err != nil {
+		return nil, &CompileError{assetPath, err}
+	}
+	return buf.Bytes(), nil
+}
@@ -1,7 +1,10 @@
 package sprockets
 
 import (
+	"text/template"
+
 	"github.com/znly/go-sprockets/assetscache"
+	"github.com/znly/go-sprockets/compressor"
 	"github.com/znly/go-sprockets/types"
 )
 
@@ -11,5 +14,21 @@ type Sprocket struct {
 	extInfos       map[string]*types.ExtensionInfo
 	defaultExtInfo *types.ExtensionInfo
 	publicPath     string
+	production     bool
 	assetsCache    *assetscache.AssetsCache
+	errorOverlay   bool
+	debug          bool
+	manifest       *Manifest
+	processors     *processorRegistry
+	cssURLRewriter bool
+	cssURLAssets   types.AssetResolver
+	dataURIMaxSize int64
+
+	templateURLPrefix string
+	templateData      map[string]interface{}
+	templateFuncs     template.FuncMap
+
+	jsDefiner     *compressor.JSDefiner
+	jsDropConsole bool
+	aliases       *aliasTable
 }
@@ -0,0 +1,9 @@
+package types
+
+// AssetResolver gives the urls of the assets to the compilers (ie: sass asset-url)
+type AssetResolver interface {
+	// AssetPath returns the url of an asset, using its digest path when found in the manifest
+	AssetPath(assetPath string) (string, error)
+	// AssetDataURL returns the full content of an asset as a data url
+	AssetDataURL(assetPath string) (string, error)
+}
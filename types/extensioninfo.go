@@ -21,4 +21,25 @@ type ExtensionInfo struct {
 	PostCompileContentTreatment []ContentTreatmentInterface
 	BundleCompiler              ContentTreatmentInterface
 	FileCompiler                ContentTreatmentInterface
+	MimeType                    string
+	OutputMimeType              string
+	Charset                     string
+	ModuleWrapping              ModuleWrapping
+	PathResolvers               []PathResolver
 }
+
+// ModuleWrapping is the way the content of each file is wrapped when bundled
+type ModuleWrapping int
+
+const (
+	// NoWrapping leaves the content as is, the files share the global scope
+	NoWrapping ModuleWrapping = iota
+	// IIFEWrapping wraps the content in an immediately invoked function
+	IIFEWrapping
+	// CommonJSWrapping wraps the content in define(name, function(require, module, exports) {...})
+	// and runs it, its exports are then available with require(name)
+	CommonJSWrapping
+	// UMDWrapping wraps the content in a universal module definition exposing its module.exports
+	// to AMD loaders, CommonJS environments or as a global variable
+	UMDWrapping
+)
@@ -0,0 +1,11 @@
+package types
+
+// ImportResolver returns the path of the file imported by parentPath, or an error if it can't be found
+type ImportResolver func(importPath, parentPath string) (string, error)
+
+// ImporterInterface can be implemented by a BundleCompiler which imports other files (ie: sass @import).
+// The imports are then resolved through the search paths of the extension
+// and the imported files are recorded as dependencies of the asset.
+type ImporterInterface interface {
+	ProcessWithImports(content []byte, path string, resolve ImportResolver) ([]byte, error)
+}
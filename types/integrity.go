@@ -0,0 +1,26 @@
+package types
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+)
+
+// Integrity holds the Subresource Integrity digests of a content, ready to be used in an integrity attribute.
+type Integrity struct {
+	SHA256 string `json:"sha256"`
+	SHA384 string `json:"sha384"`
+	SHA512 string `json:"sha512"`
+}
+
+// NewIntegrity computes the Subresource Integrity digests of content.
+func NewIntegrity(content []byte) *Integrity {
+	sum256 := sha256.Sum256(content)
+	sum384 := sha512.Sum384(content)
+	sum512 := sha512.Sum512(content)
+	return &Integrity{
+		SHA256: "sha256-" + base64.StdEncoding.EncodeToString(sum256[:]),
+		SHA384: "sha384-" + base64.StdEncoding.EncodeToString(sum384[:]),
+		SHA512: "sha512-" + base64.StdEncoding.EncodeToString(sum512[:]),
+	}
+}
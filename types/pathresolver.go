@@ -0,0 +1,9 @@
+package types
+
+// PathResolver resolves the asset paths which are not found in the paths of an extension (ie: in node_modules)
+type PathResolver interface {
+	// Resolve returns the path of the file of assetPath and its extension, which must be one of the AlterExts of extInfo
+	Resolve(assetPath string, extInfo *ExtensionInfo) (string, string, bool)
+	// LogicalPath returns the asset path resolving to realPath, false if realPath is not one of its files
+	LogicalPath(realPath string) (string, bool)
+}